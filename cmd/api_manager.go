@@ -1,46 +1,260 @@
 package main
 
 import (
-	"log"
-	"os"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
 	"syfttoymlconverter/internal"
+	"syfttoymlconverter/internal/answers"
+	"syfttoymlconverter/internal/document"
+	"syfttoymlconverter/internal/handler"
 	"syfttoymlconverter/internal/model"
+	"syfttoymlconverter/internal/provider"
+	"syfttoymlconverter/internal/rules"
+	"sync"
 
 	"github.com/goccy/go-yaml"
+	"github.com/rs/zerolog/log"
 )
 
 type Lang_Interface interface {
 	FetchMetadata(syft *internal.Syft) (model.BuildInfo, error)
 }
 
+// ecosystems maps the values accepted by --ecosystem to their handler
+var ecosystems = map[string]Lang_Interface{
+	"npm":    handler.Npm{},
+	"go":     handler.Go{},
+	"dotnet": handler.Dotnet{},
+	"conan":  handler.Conan{},
+}
+
+// purlTypes maps the purl type of an artifact to its ecosystem
+var purlTypes = map[string]string{
+	"npm":    "npm",
+	"golang": "go",
+	"dotnet": "dotnet",
+	"nuget":  "dotnet",
+	"conan":  "conan",
+}
+
 type Manager struct {
-	Lang Lang_Interface
+	// Ecosystem restricts the conversion to one ecosystem, "auto" converts all of them
+	Ecosystem string
+	Handlers  map[string]Lang_Interface
+	// Rules select the modules that become libraries, all are included without rules
+	Rules rules.Config
+	// Report lists the modules the rules excluded in the last run
+	Report rules.Report
+	// Answers renders the answers of the libraries
+	Answers *answers.Set
+	// Project is available to the answer templates
+	Project document.Project
+	// Selected holds the modules the rules selected in the last run, convert exports them
+	Selected model.BuildInfo
 }
 
-func NewManager(l Lang_Interface) *Manager {
+func NewManager(ecosystem string) *Manager {
 	return &Manager{
-		Lang: l,
+		Ecosystem: ecosystem,
+		Handlers:  ecosystems,
+		Answers:   answers.Default(),
 	}
 }
 
-func (m *Manager) Run(syft *internal.Syft) error {
-	var models model.BuildInfo
-	var libraries model.Librarys
+// Partition splits the SBOM into one part per ecosystem. Artifacts of unsupported
+// ecosystems or, if an ecosystem was selected, of other ecosystems are dropped.
+func (m *Manager) Partition(syft *internal.Syft) (map[string]*internal.Syft, error) {
+	if len(syft.Artifacts) == 0 {
+		return nil, fmt.Errorf("sbom does not contain any artifacts")
+	}
 
-	models, _ = m.Lang.FetchMetadata(syft)
+	parts := map[string]*internal.Syft{}
+	for purlType, part := range syft.Partition() {
+		ecosystem, ok := purlTypes[purlType]
+		if !ok {
+			log.Warn().Msgf("skipping %d artifacts of unsupported type %q", len(part.Artifacts), purlType)
+			continue
+		}
+		if m.Ecosystem != "auto" && m.Ecosystem != ecosystem {
+			continue
+		}
 
-	libraries = model.ModelToLibrary(&models)
+		if existing, ok := parts[ecosystem]; ok {
+			// dotnet and nuget purls end up in the same handler
+			existing.Artifacts = append(existing.Artifacts, part.Artifacts...)
+			existing.ArtifactRelationships = append(existing.ArtifactRelationships, part.ArtifactRelationships...)
+			continue
+		}
+		parts[ecosystem] = part
+	}
 
-	yamlData, yamlErr := yaml.Marshal(&libraries)
-	if yamlErr != nil {
-		log.Print(yamlErr)
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("sbom does not contain any artifacts of ecosystem %q", m.Ecosystem)
 	}
 
-	fileName := "../foss.yml"
-	yamlErr = os.WriteFile(fileName, yamlData, 0644)
+	return parts, nil
+}
+
+// FetchMetadata runs the handler of every ecosystem concurrently on its own
+// artifacts and merges the modules into one BuildInfo.
+func (m *Manager) FetchMetadata(syft *internal.Syft) (model.BuildInfo, error) {
+	parts, err := m.Partition(syft)
+	if err != nil {
+		return model.BuildInfo{}, err
+	}
+
+	names := make([]string, 0, len(parts))
+	for name := range parts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]model.BuildInfo, len(names))
+	errs := make([]error, len(names))
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		lang, ok := m.Handlers[name]
+		if !ok {
+			return model.BuildInfo{}, fmt.Errorf("no handler for ecosystem %q", name)
+		}
+
+		wg.Add(1)
+		go func(i int, lang Lang_Interface, part *internal.Syft) {
+			defer wg.Done()
+			results[i], errs[i] = lang.FetchMetadata(part)
+		}(i, lang, parts[name])
+	}
+	wg.Wait()
 
-	if yamlErr != nil {
-		log.Print(yamlErr)
+	merged := model.BuildInfo{
+		Path: syft.Source.Target,
+		Mod:  "Mod",
 	}
+	for i, name := range names {
+		if errs[i] != nil {
+			return model.BuildInfo{}, fmt.Errorf("%s: %w", name, errs[i])
+		}
+		for _, module := range results[i].Modules {
+			module.Ecosystem = name
+			merged.Modules = append(merged.Modules, module)
+		}
+	}
+
+	return merged, nil
+}
+
+// Libraries fetches the metadata of all artifacts and returns the libraries selected by the rules
+func (m *Manager) Libraries(syft *internal.Syft) (model.Librarys, error) {
+	models, err := m.FetchMetadata(syft)
+	if err != nil {
+		return model.Librarys{}, err
+	}
+	if err := checkUnresolved(&models); err != nil {
+		return model.Librarys{}, err
+	}
+	if skipped := provider.Skipped(); len(skipped) > 0 {
+		log.Warn().Msgf("%d lookups were skipped because the rate limit was exhausted, "+
+			"the affected libraries miss data:\n  %s", len(skipped), strings.Join(skipped, "\n  "))
+	}
+
+	if m.Rules.UsesScope() && !hasScope(models.Modules) {
+		log.Warn().Msg("The scope rules match no library, the SBOM records no dependency scope. " +
+			"Syft SBOMs never do, convert a CycloneDX or SPDX SBOM that records it to filter by scope.")
+	}
+	if usesGraph(m.Rules) && !hasParents(models.Modules) {
+		log.Warn().Msg("The SBOM records no dependencies between the libraries, all of them count as direct " +
+			"at depth 1 and the direct and depth rules exclude none of them.")
+	}
+	models.Modules, m.Report = m.Rules.Apply(models.Modules)
+	m.Selected = models
+	for _, e := range m.Report.Exclusions {
+		log.Info().Msgf("excluded %s: %s", e.Module, e.Reason)
+	}
+
+	libraries := model.ModelToLibrary(&models)
+	for i := range libraries.Libraries {
+		err := m.Answers.Render(&libraries.Libraries[i], models.Modules[i], m.Project)
+		if err != nil {
+			return model.Librarys{}, err
+		}
+	}
+
+	return libraries, nil
+}
+
+func writeYaml(out io.Writer, v interface{}) error {
+	yamlData, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = out.Write(yamlData)
+	return err
+}
+
+// UnresolvedError lists the modules whose metadata was missing in offline mode
+type UnresolvedError struct {
+	Modules []model.Module
+}
+
+func (e *UnresolvedError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d modules could not be resolved offline:", len(e.Modules))
+	for _, m := range e.Modules {
+		fmt.Fprintf(&b, "\n  %s (missing %s)", m.String(), strings.Join(m.Unresolved, ", "))
+	}
+
+	return b.String()
+}
+
+func checkUnresolved(models *model.BuildInfo) error {
+	var unresolved []model.Module
+	for _, m := range models.Modules {
+		if len(m.Unresolved) > 0 {
+			unresolved = append(unresolved, m)
+		}
+	}
+
+	if len(unresolved) > 0 {
+		return &UnresolvedError{Modules: unresolved}
+	}
+
 	return nil
 }
+
+// usesGraph reports whether a rule matches by the position in the dependency graph
+func usesGraph(config rules.Config) bool {
+	for _, r := range config.Rules {
+		if r.Direct != nil || r.MinDepth > 0 || r.MaxDepth > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasParents reports whether the dependency graph of the modules has any edge,
+// without one every module is a direct dependency
+func hasParents(modules []model.Module) bool {
+	for _, m := range modules {
+		if len(m.Parents) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasScope reports whether the SBOM recorded the dependency scope of any module
+func hasScope(modules []model.Module) bool {
+	for _, m := range modules {
+		if m.Scope != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"syfttoymlconverter/internal/cache"
+)
+
+const cacheUsage = `Usage: syft2yml cache <command> [flags]
+
+Commands:
+  prune   remove cached registry and forge documents
+`
+
+func cacheCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, cacheUsage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "prune":
+		return cachePrune(args[1:], stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, cacheUsage)
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "unknown cache command %q\n\n%s", args[0], cacheUsage)
+	return exitUsage
+}
+
+func cachePrune(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cache prune", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	dir := flags.String("cache-dir", cache.DefaultDir(), "directory of the metadata cache")
+	olderThan := flags.Duration("older-than", 0, "only remove entries older than this, e.g. 72h, 0 removes everything")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: syft2yml cache prune [--cache-dir dir] [--older-than 0]\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+
+	removed, err := cache.New(*dir, 0).Prune(*olderThan)
+	if err != nil {
+		fmt.Fprintln(stderr, "cache prune:", err)
+		return exitFailure
+	}
+
+	fmt.Fprintf(stdout, "removed %d entries from %s\n", removed, *dir)
+	return exitOK
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syfttoymlconverter/internal"
+	"syfttoymlconverter/internal/answers"
+	"syfttoymlconverter/internal/api_interfaces"
+	"syfttoymlconverter/internal/cache"
+	"syfttoymlconverter/internal/credentials"
+	"syfttoymlconverter/internal/document"
+	"syfttoymlconverter/internal/export"
+	"syfttoymlconverter/internal/httpclient"
+	"syfttoymlconverter/internal/model"
+	"syfttoymlconverter/internal/provider"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+type convertOptions struct {
+	In        string
+	Out       string
+	Ecosystem string
+	Hosts     string
+	CacheDir  string
+	CacheTTL  time.Duration
+	NoCache   bool
+	Refresh   bool
+	Offline   bool
+	Mirror    string
+	Proxy     string
+	Timeout   time.Duration
+	Retries   int
+	GraphQL   bool
+	MaxWait   time.Duration
+	// Manufacturer is the comma separated precedence of npm manufacturer sources
+	Manufacturer  string
+	Rules         ruleOptions
+	Merge         bool
+	MergeBase     string
+	Project       string
+	LibrariesOnly bool
+	Strict        bool
+	Templates     string
+	CycloneDX     string
+	SPDX          string
+}
+
+// addInputFlags registers the flags shared by every command reading an SBOM
+func (opts *convertOptions) addInputFlags(flags *flag.FlagSet) {
+	flags.StringVar(&opts.In, "in", "-", "SBOM to convert: syft json, CycloneDX json or xml or SPDX json or tag-value, - reads from stdin")
+	flags.StringVar(&opts.Ecosystem, "ecosystem", "auto", "only convert artifacts of one ecosystem: npm, go, dotnet or conan, auto converts all")
+	flags.StringVar(&opts.Hosts, "hosts", credentials.DefaultHostsFile(), "hosts file with the tokens and self-hosted forges of the source code hosts")
+	flags.StringVar(&opts.Proxy, "proxy", "", "proxy url for all requests, defaults to HTTPS_PROXY and HTTP_PROXY")
+	flags.DurationVar(&opts.Timeout, "http-timeout", 2*time.Minute, "timeout of a single lookup including retries")
+	flags.IntVar(&opts.Retries, "retries", 4, "how often rate limited or failed requests are repeated")
+	flags.BoolVar(&opts.GraphQL, "github-graphql", false, "batch github repository lookups through the graphql api, needs a token")
+	flags.DurationVar(&opts.MaxWait, "github-max-wait", 15*time.Minute, "longest pause for an exhausted github rate limit, lookups are skipped beyond it")
+}
+
+func convert(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var opts convertOptions
+
+	flags := flag.NewFlagSet("convert", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	opts.addInputFlags(flags)
+	flags.StringVar(&opts.Out, "out", "foss.yml", "file the yaml is written to, - writes to stdout")
+	flags.StringVar(&opts.CacheDir, "cache-dir", cache.DefaultDir(), "directory of the metadata cache")
+	flags.DurationVar(&opts.CacheTTL, "cache-ttl", cache.DefaultTTL, "age after which cached metadata is fetched again")
+	flags.BoolVar(&opts.NoCache, "no-cache", false, "do not read or write the metadata cache")
+	flags.BoolVar(&opts.Refresh, "refresh", false, "fetch all metadata again and update the cache")
+	flags.BoolVar(&opts.Offline, "offline", false, "resolve all metadata from --mirror without network access")
+	flags.StringVar(&opts.Mirror, "mirror", "", "metadata mirror written by 'syft2yml mirror export', required with --offline")
+	flags.StringVar(&opts.Manufacturer, "npm-manufacturer", strings.Join(api_interfaces.ManufacturerPrecedence, ","),
+		"people of a npm package tried for the manufacturer in this order: author, contributors, maintainers, publisher")
+	flags.StringVar(&opts.Rules.File, "rules", "", "yaml file with the rules which libraries are included")
+	flags.Var(&opts.Rules.Exclude, "exclude", "exclude packages whose name matches the glob, can be repeated")
+	flags.Var(&opts.Rules.ExcludeLicense, "exclude-license", "exclude packages whose license matches the glob, can be repeated")
+	flags.Var(&opts.Rules.ExcludeScope, "exclude-scope", "exclude packages of the dependency scopes like dev, test or excluded")
+	flags.IntVar(&opts.Rules.MaxDepth, "max-depth", 0, "exclude packages deeper in the dependency graph, 1 keeps the direct dependencies, 0 keeps all")
+	flags.BoolVar(&opts.Rules.DirectOnly, "direct-only", false, "exclude transitive dependencies")
+	flags.StringVar(&opts.Rules.Report, "report", "", "yaml file listing every excluded library and why")
+	flags.BoolVar(&opts.Merge, "merge", false, "merge into the existing --out file and keep the manual edits")
+	flags.StringVar(&opts.MergeBase, "merge-base", "", "libraries generated by the previous merge, defaults to the --out file with .base appended")
+	flags.StringVar(&opts.Project, "project", "", "yaml file with header, front page and referenced documents, defaults to those of the existing --out file")
+	flags.BoolVar(&opts.LibrariesOnly, "libraries-only", false, "only write the libraries instead of the complete document")
+	flags.StringVar(&opts.Templates, "templates", "", "directory with answer1.tmpl to answer6.tmpl replacing the built-in answer templates")
+	flags.StringVar(&opts.CycloneDX, "cyclonedx", "", "also write a CycloneDX 1.5 json BOM of the libraries to this file")
+	flags.StringVar(&opts.SPDX, "spdx", "", "also write an SPDX 2.3 document of the libraries to this file, json if it ends in .json, else tag-value")
+	flags.BoolVar(&opts.Strict, "strict", false, "fail if required fields of the document are missing, the file is still written")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: syft2yml convert [--in sbom.json] [--out foss.yml] [--ecosystem auto]\n\n")
+		flags.PrintDefaults()
+	}
+
+	if code, ok := parseFlags(flags, args, stderr); !ok {
+		return code
+	}
+	if _, ok := ecosystems[opts.Ecosystem]; !ok && opts.Ecosystem != "auto" {
+		fmt.Fprintf(stderr, "unknown ecosystem %q\n", opts.Ecosystem)
+		flags.Usage()
+		return exitUsage
+	}
+	precedence, err := api_interfaces.ParseManufacturerPrecedence(opts.Manufacturer)
+	if err != nil {
+		fmt.Fprintln(stderr, "--npm-manufacturer:", err)
+		flags.Usage()
+		return exitUsage
+	}
+	api_interfaces.ManufacturerPrecedence = precedence
+	if opts.Merge && opts.Out == "-" {
+		fmt.Fprintln(stderr, "--merge requires an --out file")
+		flags.Usage()
+		return exitUsage
+	}
+	if opts.Offline && opts.Mirror == "" {
+		fmt.Fprintln(stderr, "--offline requires --mirror")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if err := runConvert(opts, stdin, stdout); err != nil {
+		fmt.Fprintln(stderr, "convert:", err)
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+// parseFlags parses args, ok is false if the command should exit with code
+func parseFlags(flags *flag.FlagSet, args []string, stderr io.Writer) (int, bool) {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	if flags.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %v\n", flags.Args())
+		flags.Usage()
+		return exitUsage, false
+	}
+
+	return exitOK, true
+}
+
+func runConvert(opts convertOptions, stdin io.Reader, stdout io.Writer) error {
+	syft, err := readSyft(opts.In, stdin)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", opts.In, err)
+	}
+
+	manager := NewManager(opts.Ecosystem)
+	manager.Rules, err = opts.Rules.config()
+	if err != nil {
+		return fmt.Errorf("rules: %w", err)
+	}
+	var project *document.Project
+	if opts.Project != "" {
+		p, err := document.LoadProject(opts.Project)
+		if err != nil {
+			return fmt.Errorf("project: %w", err)
+		}
+		project = &p
+	}
+	var previous *model.Document
+	if opts.Out != "-" {
+		doc, ok, err := document.Read(opts.Out)
+		switch {
+		case err != nil && opts.Merge:
+			return fmt.Errorf("reading previous document: %w", err)
+		case err != nil:
+			log.Warn().Err(err).Msgf("ignoring the previous document %s", opts.Out)
+		case ok:
+			previous = &doc
+		}
+	}
+
+	manager.Answers, err = answers.Load(opts.Templates)
+	if err != nil {
+		return fmt.Errorf("templates: %w", err)
+	}
+	switch {
+	case project != nil:
+		manager.Project = *project
+	case previous != nil:
+		manager.Project = document.Project{
+			Header:              previous.Header,
+			FrontPage:           previous.FrontPage,
+			ReferencedDocuments: previous.ReferencedDocuments,
+		}
+	}
+
+	if opts.Offline {
+		cache.Default = cache.NewMirror(opts.Mirror, true)
+	} else {
+		cache.Default = &cache.Cache{
+			Dir:      opts.CacheDir,
+			TTL:      opts.CacheTTL,
+			Refresh:  opts.Refresh,
+			Disabled: opts.NoCache,
+		}
+	}
+	if err := setupClients(opts); err != nil {
+		return err
+	}
+
+	generated, err := manager.Libraries(syft)
+	if err != nil {
+		return err
+	}
+	if err := opts.Rules.writeReport(manager.Report); err != nil {
+		return fmt.Errorf("writing report: %w", err)
+	}
+
+	libraries := generated.Libraries
+	if opts.Merge {
+		var existing []model.Library
+		if previous != nil {
+			existing = previous.Libraries
+		}
+		libraries, err = mergeLibraries(opts, existing, generated.Libraries)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.CycloneDX != "" {
+		if err := writeExport(opts.CycloneDX, manager.Selected, export.WriteCycloneDX); err != nil {
+			return fmt.Errorf("writing CycloneDX: %w", err)
+		}
+	}
+	if opts.SPDX != "" {
+		write := export.WriteSPDXTagValue
+		if strings.HasSuffix(opts.SPDX, ".json") {
+			write = export.WriteSPDXJSON
+		}
+		if err := writeExport(opts.SPDX, manager.Selected, write); err != nil {
+			return fmt.Errorf("writing SPDX: %w", err)
+		}
+	}
+
+	var output interface{} = &model.Librarys{Libraries: libraries}
+	if !opts.LibrariesOnly {
+		doc := document.Build(project, previous, libraries, syft.Source.Target, time.Now())
+		output = &doc
+	}
+
+	// render into memory first so a failed run does not truncate an existing file
+	var buf bytes.Buffer
+	if err := writeYaml(&buf, output); err != nil {
+		return err
+	}
+
+	if opts.Out == "-" {
+		if _, err := buf.WriteTo(stdout); err != nil {
+			return err
+		}
+	} else {
+		if err := os.WriteFile(opts.Out, buf.Bytes(), 0644); err != nil {
+			return err
+		}
+		if opts.Merge {
+			if err := writeMergeBase(opts, generated); err != nil {
+				return err
+			}
+		}
+	}
+
+	return checkOutput(output, opts.Strict)
+}
+
+// writeExport writes the SBOM of the selected modules to path
+func writeExport(path string, info model.BuildInfo, write func(io.Writer, model.BuildInfo, time.Time) error) error {
+	var buf bytes.Buffer
+	if err := write(&buf, info, time.Now()); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// setupClients configures the http client and the providers, it has to run before the first lookup
+func setupClients(opts convertOptions) error {
+	retries := opts.Retries
+	if retries == 0 {
+		// zero means the default for httpclient
+		retries = -1
+	}
+
+	client, err := httpclient.New(httpclient.Options{
+		Timeout: opts.Timeout,
+		Retries: retries,
+		Proxy:   opts.Proxy,
+	})
+	if err != nil {
+		return err
+	}
+	httpclient.Default = client
+
+	provider.SetCredentials(credentials.Default(opts.Hosts))
+	provider.EnableBatching(opts.GraphQL)
+	limits := provider.DefaultRateLimitOptions
+	limits.MaxWait = opts.MaxWait
+	provider.SetRateLimitOptions(limits)
+
+	return provider.LoadHosts(opts.Hosts)
+}
+
+// readSyft reads the SBOM at path in any supported format
+func readSyft(path string, stdin io.Reader) (*internal.Syft, error) {
+	if path == "-" {
+		return internal.ReadSBOM(stdin)
+	}
+
+	return internal.ReadSBOMFile(path)
+}
@@ -1,34 +1,61 @@
 package main
 
 import (
-	"log"
-	"strings"
-	"syfttoymlconverter/internal"
-	"syfttoymlconverter/internal/handler"
+	"fmt"
+	"io"
+	"os"
 )
 
+// exit codes of the cli
+const (
+	exitOK      = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+const usageText = `Usage: syft2yml <command> [flags]
+
+Converts a syft SBOM into the foss.yml consumed by the OTS document generator.
+
+Commands:
+  convert   convert a syft json SBOM into foss.yml
+  cache     manage the on-disk metadata cache
+  mirror    export a metadata mirror for offline conversion
+  validate  check that all required fields of a foss.yml are filled
+  help      show this help
+
+Run 'syft2yml <command> --help' for the flags of a command.
+
+Exit codes:
+  0  success
+  1  the conversion or validation failed
+  2  invalid usage
+`
+
 func main() {
-	syftReader := internal.Syft{}
-	var manager *Manager
-	syft, syftErr := syftReader.ReadJson("../testfiles/dependencies_angular.json")
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
 
-	if syftErr != nil {
-		log.Print(syftErr)
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usageText)
+		return exitUsage
 	}
 
-	switch {
-	case strings.Contains(syft.Artifacts[0].Purl, "dotnet"):
-		manager = NewManager(handler.Dotnet{})
-	case strings.Contains(syft.Artifacts[0].Purl, "golang"):
-		manager = NewManager(handler.Go{})
-	case strings.Contains(syft.Artifacts[0].Purl, "npm"):
-		manager = NewManager(handler.Npm{})
-	case strings.Contains(syft.Artifacts[0].Purl, "conan"):
-		manager = NewManager(handler.Conan{})
+	switch args[0] {
+	case "convert":
+		return convert(args[1:], stdin, stdout, stderr)
+	case "cache":
+		return cacheCommand(args[1:], stdout, stderr)
+	case "mirror":
+		return mirrorCommand(args[1:], stdin, stdout, stderr)
+	case "validate":
+		return validateCommand(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usageText)
+		return exitOK
 	}
 
-	err := manager.Run(syft)
-	if err != nil {
-		log.Println(err)
-	}
+	fmt.Fprintf(stderr, "unknown command %q\n\n%s", args[0], usageText)
+	return exitUsage
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"syfttoymlconverter/internal/merge"
+	"syfttoymlconverter/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// mergeBasePath returns the file the generated libraries of the last merge are kept in
+func mergeBasePath(opts convertOptions) string {
+	if opts.MergeBase != "" {
+		return opts.MergeBase
+	}
+
+	return opts.Out + ".base"
+}
+
+// mergeLibraries merges the generated libraries into the existing ones of the --out file
+func mergeLibraries(opts convertOptions, existing, generated []model.Library) ([]model.Library, error) {
+	basePath := mergeBasePath(opts)
+	base, hasBase, err := merge.Read(basePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 && !hasBase {
+		log.Warn().Msgf("no merge base %s, differing curated fields of %s are kept as manual edits", basePath, opts.Out)
+	}
+
+	result := merge.Merge(existing, base, generated)
+	for _, c := range result.Conflicts {
+		log.Warn().Msgf("conflict: %s", c)
+	}
+	log.Info().Msgf("merged %s: %d added, %d updated, %d removed, %d conflicts",
+		opts.Out, result.Added, result.Updated, result.Removed, len(result.Conflicts))
+
+	return result.Libraries, nil
+}
+
+// writeMergeBase stores the generated libraries as base of the next merge
+func writeMergeBase(opts convertOptions, generated model.Librarys) error {
+	var buf bytes.Buffer
+	if err := writeYaml(&buf, &generated); err != nil {
+		return err
+	}
+	if err := os.WriteFile(mergeBasePath(opts), buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("writing merge base: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"syfttoymlconverter/internal/cache"
+)
+
+const mirrorUsage = `Usage: syft2yml mirror <command> [flags]
+
+Commands:
+  export   download all metadata of an SBOM into a mirror directory for 'convert --offline'
+
+Forge responses fetched with a token are only served to offline runs with the same token.
+`
+
+func mirrorCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, mirrorUsage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "export":
+		return mirrorExport(args[1:], stdin, stdout, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, mirrorUsage)
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "unknown mirror command %q\n\n%s", args[0], mirrorUsage)
+	return exitUsage
+}
+
+func mirrorExport(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var opts convertOptions
+
+	flags := flag.NewFlagSet("mirror export", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	opts.addInputFlags(flags)
+	flags.StringVar(&opts.Mirror, "dir", "", "directory the mirror is written to")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: syft2yml mirror export --dir mirror [--in sbom.json] [--ecosystem auto]\n\n")
+		flags.PrintDefaults()
+	}
+
+	if code, ok := parseFlags(flags, args, stderr); !ok {
+		return code
+	}
+	if opts.Mirror == "" {
+		fmt.Fprintln(stderr, "--dir is required")
+		flags.Usage()
+		return exitUsage
+	}
+
+	if err := runMirrorExport(opts, stdin, stdout); err != nil {
+		fmt.Fprintln(stderr, "mirror export:", err)
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func runMirrorExport(opts convertOptions, stdin io.Reader, stdout io.Writer) error {
+	syft, err := readSyft(opts.In, stdin)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", opts.In, err)
+	}
+
+	cache.Default = cache.NewMirror(opts.Mirror, false)
+	if err := setupClients(opts); err != nil {
+		return err
+	}
+
+	models, err := NewManager(opts.Ecosystem).FetchMetadata(syft)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "exported the metadata of %d modules to %s\n", len(models.Modules), opts.Mirror)
+	return nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syfttoymlconverter/internal/rules"
+
+	"github.com/goccy/go-yaml"
+)
+
+// stringList is a flag that can be repeated and also takes comma separated values
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*l = append(*l, v)
+		}
+	}
+
+	return nil
+}
+
+// ruleOptions are the inclusion rules given on the command line
+type ruleOptions struct {
+	File           string
+	Exclude        stringList
+	ExcludeLicense stringList
+	ExcludeScope   stringList
+	MaxDepth       int
+	DirectOnly     bool
+	Report         string
+}
+
+// config loads the rules file and appends the rules of the flags, so the file can
+// include exceptions before them
+func (opts ruleOptions) config() (rules.Config, error) {
+	var config rules.Config
+	if opts.File != "" {
+		var err error
+		config, err = rules.Load(opts.File)
+		if err != nil {
+			return config, err
+		}
+	}
+
+	if len(opts.Exclude) > 0 {
+		config.Rules = append(config.Rules, rules.Rule{
+			Action: rules.Exclude,
+			Reason: "excluded by --exclude",
+			Name:   opts.Exclude,
+		})
+	}
+	if len(opts.ExcludeLicense) > 0 {
+		config.Rules = append(config.Rules, rules.Rule{
+			Action:  rules.Exclude,
+			Reason:  "license excluded by --exclude-license",
+			License: opts.ExcludeLicense,
+		})
+	}
+	if len(opts.ExcludeScope) > 0 {
+		config.Rules = append(config.Rules, rules.Rule{
+			Action: rules.Exclude,
+			Reason: "scope excluded by --exclude-scope",
+			Scope:  opts.ExcludeScope,
+		})
+	}
+	if opts.MaxDepth > 0 {
+		config.Rules = append(config.Rules, rules.Rule{
+			Action:   rules.Exclude,
+			Reason:   fmt.Sprintf("deeper than --max-depth %d", opts.MaxDepth),
+			MinDepth: opts.MaxDepth + 1,
+		})
+	}
+	if opts.DirectOnly {
+		direct := false
+		config.Rules = append(config.Rules, rules.Rule{
+			Action: rules.Exclude,
+			Reason: "transitive dependency excluded by --direct-only",
+			Direct: &direct,
+		})
+	}
+
+	return config, config.Validate()
+}
+
+// writeReport writes the exclusions of the run as yaml to the report file
+func (opts ruleOptions) writeReport(report rules.Report) error {
+	if opts.Report == "" {
+		return nil
+	}
+
+	data, err := yaml.Marshal(&report)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(opts.Report, data, 0644)
+}
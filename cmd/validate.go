@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"syfttoymlconverter/internal/model"
+	"syfttoymlconverter/internal/validate"
+
+	"github.com/goccy/go-yaml"
+	"github.com/rs/zerolog/log"
+)
+
+func validateCommand(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	var librariesOnly bool
+
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.BoolVar(&librariesOnly, "libraries-only", false, "the file only contains the libraries, not the complete document")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), "Usage: syft2yml validate [--libraries-only] foss.yml\n\n"+
+			"Checks that all required fields are filled, - reads from stdin. Exits with 1 if any is missing.\n\n")
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	path := flags.Arg(0)
+	data, err := readInput(path, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "validate:", err)
+		return exitFailure
+	}
+
+	var doc interface{} = &model.Document{}
+	if librariesOnly {
+		doc = &model.Librarys{}
+	}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		fmt.Fprintf(stderr, "validate: parsing %s: %v\n", path, err)
+		return exitFailure
+	}
+
+	violations := validate.Struct(doc)
+	for _, v := range violations {
+		fmt.Fprintln(stdout, v)
+	}
+	if len(violations) > 0 {
+		fmt.Fprintf(stderr, "%s: %d required fields are missing\n", path, len(violations))
+		return exitFailure
+	}
+
+	return exitOK
+}
+
+func readInput(path string, stdin io.Reader) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(stdin)
+	}
+
+	return os.ReadFile(path)
+}
+
+// checkOutput validates the generated document, in strict mode missing fields are an error
+func checkOutput(output interface{}, strict bool) error {
+	violations := validate.Struct(output)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	for _, v := range violations {
+		log.Warn().Msgf("missing %s", v)
+	}
+	if strict {
+		return fmt.Errorf("%d required fields are missing", len(violations))
+	}
+	log.Warn().Msgf("%d required fields are missing, fill them in before generating the document", len(violations))
+
+	return nil
+}
@@ -4,6 +4,7 @@ go 1.20
 
 require (
 	github.com/google/go-github/v37 v37.0.0
+	github.com/santhosh-tekuri/jsonschema/v5 v5.3.1
 	gopkg.in/yaml.v2 v2.4.0
 )
 
@@ -0,0 +1,116 @@
+// Package answers renders the SOUP answers of the libraries from text/template files.
+// The built-in templates in templates/ hold the default wording, a template
+// directory can replace any of them with a file of the same name.
+package answers
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syfttoymlconverter/internal/document"
+	"syfttoymlconverter/internal/model"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var defaults embed.FS
+
+// names of the template files, answer1.tmpl renders Answer1 and so on
+//
+//nolint:gochecknoglobals // constant list of the template files
+var names = []string{"answer1.tmpl", "answer2.tmpl", "answer3.tmpl", "answer4.tmpl", "answer5.tmpl", "answer6.tmpl"}
+
+// Data is passed to the templates
+type Data struct {
+	// Module is the resolved package including its parents and repository info
+	Module model.Module
+	// Info is the repository info of the module
+	Info model.RepoInfo
+	// Parents are the names of the packages depending on the module
+	Parents []string
+	// Library holds the table values like manufacturer and license as written to the document
+	Library model.TableMainTemplate
+	// Project is the project configuration, empty if there is none
+	Project document.Project
+}
+
+//nolint:gochecknoglobals // functions available in the templates
+var funcs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// Set are the six answer templates
+type Set struct {
+	templates [6]*template.Template
+}
+
+// Default returns the built-in templates
+func Default() *Set {
+	set, err := Load("")
+	if err != nil {
+		panic(err)
+	}
+
+	return set
+}
+
+// Load returns the built-in templates with those of dir replacing them, dir may be empty
+func Load(dir string) (*Set, error) {
+	set := &Set{}
+	for i, name := range names {
+		text, err := defaults.ReadFile("templates/" + name)
+		if err != nil {
+			return nil, err
+		}
+		source := "built-in " + name
+
+		if dir != "" {
+			custom, err := os.ReadFile(filepath.Join(dir, name))
+			switch {
+			case err == nil:
+				text, source = custom, filepath.Join(dir, name)
+			case !os.IsNotExist(err):
+				return nil, err
+			}
+		}
+
+		tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(text))
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", source, err)
+		}
+		set.templates[i] = tmpl
+	}
+
+	return set, nil
+}
+
+// Render fills the answers of lib. The final newline of a template file is not part of the answer.
+func (s *Set) Render(lib *model.Library, module model.Module, project document.Project) error {
+	data := Data{
+		Module:  module,
+		Info:    module.Info,
+		Parents: module.Parents,
+		Library: lib.LibraryData,
+		Project: project,
+	}
+
+	answers := []*string{
+		&lib.LibraryData.Answer1,
+		&lib.LibraryData.Answer2,
+		&lib.LibraryData.Answer3,
+		&lib.LibraryData.Answer4,
+		&lib.LibraryData.Answer5,
+		&lib.LibraryData.Answer6,
+	}
+	for i, tmpl := range s.templates {
+		var b strings.Builder
+		if err := tmpl.Execute(&b, data); err != nil {
+			return fmt.Errorf("%s of %s: %w", names[i], lib.Name(), err)
+		}
+		*answers[i] = strings.TrimSuffix(b.String(), "\n")
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package api_interfaces
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"syfttoymlconverter/internal"
+	"syfttoymlconverter/internal/purl"
+)
+
+// artifactName returns the package name as its ecosystem writes it, e.g. @angular/core.
+// It is taken from the purl, name is the fallback for artifacts without a valid one.
+func artifactName(purlString, name string) string {
+	p, err := purl.Parse(purlString)
+	if err != nil {
+		return name
+	}
+
+	return p.FullName()
+}
+
+// majorVersion returns the version up to the first dot, e.g. v1 for v1.2.3
+func majorVersion(version string) string {
+	if i := strings.Index(version, "."); i >= 0 {
+		return version[:i]
+	}
+
+	return version
+}
+
+// hashes returns the checksums of the artifact by algorithm: the digests of the SBOM,
+// the integrity of a package-lock entry and the sha512 of a deps.json entry
+func hashes(a internal.Artifact) map[string]string {
+	result := map[string]string{}
+	for _, d := range a.Digests {
+		result[hashAlgorithm(d.Algorithm)] = strings.ToLower(d.Value)
+	}
+
+	var integrity string
+	switch metadata := a.Metadata.(type) {
+	case internal.NpmMetadata:
+		integrity = metadata.Integrity
+	case internal.DotnetMetadata:
+		integrity = metadata.Sha512
+	}
+	// an integrity lists one or more <algorithm>-<base64> separated by spaces
+	for _, entry := range strings.Fields(integrity) {
+		algorithm, encoded, ok := strings.Cut(entry, "-")
+		if !ok {
+			continue
+		}
+		sum, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		result[hashAlgorithm(algorithm)] = hex.EncodeToString(sum)
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+// hashAlgorithm returns the algorithm as CycloneDX names it, e.g. SHA-512 for sha512 or SHA512
+// and BLAKE2b-256 for blake2b256
+func hashAlgorithm(algorithm string) string {
+	algorithm = strings.ToUpper(strings.TrimSpace(algorithm))
+	switch {
+	case strings.HasPrefix(algorithm, "BLAKE2B"):
+		return "BLAKE2b-" + strings.TrimPrefix(algorithm[len("BLAKE2B"):], "-")
+	case strings.HasPrefix(algorithm, "SHA3"):
+		if !strings.HasPrefix(algorithm, "SHA3-") {
+			return "SHA3-" + algorithm[len("SHA3"):]
+		}
+	case strings.HasPrefix(algorithm, "SHA") && !strings.HasPrefix(algorithm, "SHA-"):
+		return "SHA-" + algorithm[len("SHA"):]
+	}
+
+	return algorithm
+}
@@ -2,6 +2,7 @@ package api_interfaces
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 )
@@ -32,7 +33,7 @@ func GetMetadata(packageName string, version string) ([]byte, error) {
 	cmd := exec.Command("conan", "inspect", fmt.Sprintf("%s/%s@", packageName, version))
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		fmt.Printf("Error executing command: %s\n", err.Error())
+		fmt.Fprintf(os.Stderr, "Error executing command: %s\n", err.Error())
 		return nil, err
 	}
 	// conanInfo := parseConanOutput(string(output))
@@ -0,0 +1,49 @@
+package api_interfaces
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syfttoymlconverter/internal/cache"
+	"syfttoymlconverter/internal/httpclient"
+	"syfttoymlconverter/internal/model"
+)
+
+// getData downloads url or returns the cached document
+func getData(url string) ([]byte, error) {
+	return getDocument(url, "")
+}
+
+// getDocument downloads the representation of url selected by the accept header
+// or returns the cached document
+func getDocument(url, accept string) ([]byte, error) {
+	key := cache.Key(url, accept)
+	if body, ok := cache.Default.Get(key); ok {
+		return body, nil
+	}
+	if cache.Default.Offline {
+		return nil, &cache.MissingError{Key: url}
+	}
+
+	var header http.Header
+	if accept != "" {
+		header = http.Header{"Accept": {accept}}
+	}
+
+	body, err := httpclient.Default.Get(context.Background(), url, header)
+	if err != nil {
+		return nil, err
+	}
+
+	cache.Default.Put(key, body)
+
+	return body, nil
+}
+
+// markUnresolved records the missing document at module if err was caused by offline mode
+func markUnresolved(module *model.Module, err error) {
+	var missing *cache.MissingError
+	if errors.As(err, &missing) && !contains(module.Unresolved, missing.Key) {
+		module.Unresolved = append(module.Unresolved, missing.Key)
+	}
+}
@@ -11,10 +11,13 @@ import (
 )
 
 type Go struct {
-	Path    string // Import path, such as "github.com/mitchellh/golicense"
-	SubPath string // matches the trailing import version specifiers like `/v12`
-	Version string // Version like "v1.2.3"
-	Hash    string // Hash such as "h1:abcd1234"
+	Path    string            // Import path, such as "github.com/mitchellh/golicense"
+	SubPath string            // matches the trailing import version specifiers like `/v12`
+	Version string            // Version like "v1.2.3"
+	Hash    string            // Hash such as "h1:abcd1234"
+	Purl    string            // Package url such as "pkg:golang/github.com/pkg/errors@v0.9.1"
+	Scope   string            // Dependency scope like "dev" if the SBOM records it
+	Hashes  map[string]string // Checksums by algorithm such as "SHA-512"
 }
 
 func SyftToModule(syft *internal.Syft) ([]Go, error) {
@@ -24,10 +27,13 @@ func SyftToModule(syft *internal.Syft) ([]Go, error) {
 		// if i == 0 {
 		// 	continue
 		// }
-		data.Name = removeExtraPath(data.Name)
+		data.Name = removeExtraPath(artifactName(data.Purl, data.Name))
 		next := Go{
 			Path:    data.Name,
-			SubPath: data.Version[:strings.Index(data.Version, ".")],
+			Purl:    data.Purl,
+			Scope:   data.Scope,
+			Hashes:  hashes(data),
+			SubPath: majorVersion(data.Version),
 			Version: data.Version,
 			Hash:    data.ID,
 		}
@@ -45,7 +51,7 @@ func ParseEmbeddedModules(syft *internal.Syft) (model.BuildInfo, error) {
 	}
 
 	return model.BuildInfo{
-		Path:    syft.Artifacts[0].Locations[0].Path,
+		Path:    syft.Path(),
 		Mod:     "Mod",
 		Modules: toModule(modules),
 	}, nil
@@ -60,6 +66,9 @@ func toModule(modules []Go) []model.Module {
 			SubPath: m.SubPath,
 			Version: m.Version,
 			Hash:    m.Hash,
+			Purl:    m.Purl,
+			Scope:   m.Scope,
+			Hashes:  m.Hashes,
 		})
 	}
 
@@ -67,6 +76,8 @@ func toModule(modules []Go) []model.Module {
 }
 
 func SetRepoInfoPooled(info *model.BuildInfo, workers int) {
+	provider.PrefetchModuleInfo(info.Modules)
+
 	wp := workerpool.New(workers)
 
 	for i := range info.Modules {
@@ -75,7 +86,9 @@ func SetRepoInfoPooled(info *model.BuildInfo, workers int) {
 		wp.Submit(func() {
 			log.Info().Msgf("fetching module info for %s", module.String())
 
-			module.Info = provider.FetchModuleInfo(module.Path, module.Version)
+			info, err := provider.FetchModuleInfo(module.Path, module.Version)
+			markUnresolved(module, err)
+			module.Info = info
 		})
 	}
 
@@ -0,0 +1,22 @@
+package api_interfaces
+
+import (
+	"syfttoymlconverter/internal/graph"
+	"syfttoymlconverter/internal/model"
+)
+
+// SetGraph sets the parents, depth and whether a module is a direct dependency
+// from the graph. Modules are matched to the nodes by their artifact id.
+func SetGraph(info *model.BuildInfo, g *graph.Graph) {
+	for i := range info.Modules {
+		module := &info.Modules[i]
+		if _, ok := g.Node(module.Hash); !ok {
+			continue
+		}
+
+		module.Parents = g.Parents(module.Hash)
+		module.ParentIDs = append([]string(nil), g.Dependents(module.Hash)...)
+		module.Direct = g.IsDirect(module.Hash)
+		module.Depth = g.Depth(module.Hash)
+	}
+}
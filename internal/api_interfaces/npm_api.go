@@ -3,89 +3,65 @@ package api_interfaces
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
 	"strings"
 	"syfttoymlconverter/internal"
+	"syfttoymlconverter/internal/cache"
+	"syfttoymlconverter/internal/graph"
 	"syfttoymlconverter/internal/model"
+	"time"
 
 	"github.com/TwiN/go-color"
 	"github.com/gammazero/workerpool"
-	"gopkg.in/yaml.v2"
 )
 
-type Author struct {
-	Name string `json:"name"`
-	URL  string `json:"url"`
+// Packument is the registry document of a package with all its versions.
+// The release dates are only part of this document, not of the version specific one.
+type Packument struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	DistTags    map[string]string `json:"dist-tags"`
+	Versions    map[string]NPM    `json:"versions"`
+	// version -> publish time, plus "created" and "modified". Unpublished
+	// packages have an object under "unpublished", so values are not all strings.
+	Time map[string]interface{} `json:"time"`
 }
 
-// because the release date is not in the version specific api call
-// we have to make a generall request and parse by the version
-type newNPM struct {
-	Name        string             `json:"name"`
-	Description string             `json:"description"`
-	Versions    map[string]Version `json:"versions"`
-	Time        map[string]string  `json:"time"`
-}
-
-type Version struct {
-	Name    string      `json:"name"`
-	Version string      `json:"version"`
-	Author  interface{} `json:"author"`
-	License string      `json:"license"`
-}
-
-func (npm newNPM) WriteData() {
-	req, err := http.NewRequest("GET", "https://registry.npmjs.org/zone.js", nil)
-	if err != nil {
-		fmt.Println("[", color.Colorize(color.Red, "Err"), "] ", err)
-	}
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-		fmt.Println("[", color.Colorize(color.Red, "Err"), "] ", err)
-
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			return
-		}
-	}(res.Body)
-
-	body, readErr := io.ReadAll(res.Body)
-	if readErr != nil {
-		fmt.Println("[", color.Colorize(color.Red, "Err"), "] ", readErr)
+// accept header selecting the abbreviated packument, which only carries what is
+// needed to install a version (dependencies, dist) and is much smaller
+const abbreviatedPackument = "application/vnd.npm.install-v1+json"
 
+// ReleaseDate returns the publish time of version
+func (p Packument) ReleaseDate(version string) (time.Time, bool) {
+	value, ok := p.Time[version].(string)
+	if !ok {
+		return time.Time{}, false
 	}
 
-	err = json.Unmarshal(body, &npm)
+	release, err := time.Parse(time.RFC3339, value)
 	if err != nil {
-		fmt.Println("[", color.Colorize(color.Red, "Err"), "] ", err)
-	}
-
-	yamlData, yamlErr := yaml.Marshal(&npm)
-	if yamlErr != nil {
-		log.Print(yamlErr)
+		return time.Time{}, false
 	}
 
-	fileName2 := "../npm.yml"
-	yamlErr = os.WriteFile(fileName2, yamlData, 0644)
-
-	if yamlErr != nil {
-		log.Print(yamlErr)
-	}
+	return release.Local(), true
 }
 
 type NPM struct {
-	Name          string      `json:"name"`
-	Version       string      `json:"version"`
-	Description   string      `json:"description"`
-	Author        interface{} `json:"author"`
-	Homepage      string      `json:"homepage"`
-	License       string      `json:"license"`
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	// Author, Contributors and Maintainers are a Person, either as object or
+	// as "Name <email> (url)" string, or a list of them
+	Author       interface{} `json:"author"`
+	Contributors interface{} `json:"contributors"`
+	Homepage     string      `json:"homepage"`
+	// License is a SPDX expression, old packages use an object with a type
+	// or the deprecated Licenses list instead
+	License  interface{} `json:"license"`
+	Licenses interface{} `json:"licenses"`
+	// Repository is a url or shorthand string or an object with type, url and directory
+	Repository    interface{} `json:"repository"`
 	PublishConfig struct {
 		Access string `json:"access"`
 	} `json:"publishConfig"`
@@ -105,16 +81,11 @@ type NPM struct {
 			Sig   string `json:"sig"`
 		} `json:"signatures"`
 	} `json:"dist"`
-	NpmUser struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
-	} `json:"_npmUser"`
+	// NpmUser is the account that published the version
+	NpmUser     interface{} `json:"_npmUser"`
 	Directories struct {
 	} `json:"directories"`
-	Maintainers []struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
-	} `json:"maintainers"`
+	Maintainers interface{} `json:"maintainers"`
 }
 
 func (npm NPM) ParseEmbeddedModules(syft *internal.Syft) (model.BuildInfo, error) {
@@ -125,7 +96,7 @@ func (npm NPM) ParseEmbeddedModules(syft *internal.Syft) (model.BuildInfo, error
 	}
 
 	return model.BuildInfo{
-		Path:    syft.Artifacts[0].Locations[0].Path,
+		Path:    syft.Path(),
 		Mod:     "Mod",
 		Modules: npm.toModule(modules),
 	}, nil
@@ -134,13 +105,16 @@ func (npm NPM) ParseEmbeddedModules(syft *internal.Syft) (model.BuildInfo, error
 func (npm NPM) SyftToModule(syft *internal.Syft) ([]Module, error) {
 	var result []Module
 	for _, data := range syft.Artifacts {
-		data.Name = npm.createPath(data.Name)
+		data.Name = npm.createPath(artifactName(data.Purl, data.Name))
 		next := Module{
 			Path: data.Name,
+			Purl: data.Purl,
 			//subpath is maybe not needed
-			SubPath: data.Version[:strings.Index(data.Version, ".")],
+			SubPath: majorVersion(data.Version),
 			Version: data.Version,
 			Hash:    data.ID,
+			Scope:   data.Scope,
+			Hashes:  hashes(data),
 		}
 
 		result = append(result, next)
@@ -158,6 +132,9 @@ func (NPM) toModule(modules []Module) []model.Module {
 			SubPath: m.SubPath,
 			Version: m.Version,
 			Hash:    m.Hash,
+			Purl:    m.Purl,
+			Scope:   m.Scope,
+			Hashes:  m.Hashes,
 		})
 	}
 
@@ -167,73 +144,137 @@ func (NPM) toModule(modules []Module) []model.Module {
 func (npm NPM) SetRepoInfo(syft *internal.Syft, info *model.BuildInfo) {
 	wp := workerpool.New(10)
 
+	// one job per package, so the packument is fetched once for all its versions
+	var order []string
+	byPackage := map[string][]*model.Module{}
 	for i := range info.Modules {
 		module := &info.Modules[i]
+		pkgName := npm.getNameFromPath(module.Path)
+		if _, ok := byPackage[pkgName]; !ok {
+			order = append(order, pkgName)
+		}
+		byPackage[pkgName] = append(byPackage[pkgName], module)
+	}
+
+	for _, pkgName := range order {
+		pkgName, modules := pkgName, byPackage[pkgName]
 		wp.Submit(func() {
-			pkgName := npm.getNameFromPath(module.Path)
-			url := npm.CreateAPILink(pkgName, module.Version)
-			fmt.Println("[", color.Colorize(color.Yellow, "Fetch"), "] Module:", pkgName, "from:", url)
-			pkgData, err := npm.GetData(url)
+			url := npm.CreatePackumentLink(pkgName)
+			fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Yellow, "Fetch"), "] Module:", pkgName, "from:", url)
+			packument, err := npm.GetPackument(pkgName, false)
 			if err != nil {
 				log.Print(err)
+				for _, module := range modules {
+					markUnresolved(module, err)
+				}
+				return
 			}
-			err = npm.SetInfoToModule(module, pkgData)
-			if err != nil {
-				log.Print(err)
+			for _, module := range modules {
+				err = npm.SetInfoFromPackument(module, packument)
+				if err != nil {
+					log.Print(err)
+				}
 			}
 		})
 	}
 	wp.StopWait()
-	fmt.Println("[", color.Colorize(color.Green, "Succ"), "] All Modules were parsed ")
+	fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Green, "Succ"), "] All Modules were parsed ")
 }
 
-func (NPM) GetData(url string) ([]byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+// GetPackument fetches the packument of packageName. The abbreviated document
+// is requested unless the full one was already fetched.
+func (npm NPM) GetPackument(packageName string, abbreviated bool) (Packument, error) {
+	var packument Packument
+
+	url := npm.CreatePackumentLink(packageName)
+	pkgData, ok := cache.Default.Get(url)
+	if !ok {
+		var err error
+		if abbreviated {
+			pkgData, err = getDocument(url, abbreviatedPackument)
+		} else {
+			pkgData, err = getData(url)
+		}
+		if err != nil {
+			return packument, err
+		}
 	}
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
 
-		return nil, err
-	}
+	err := json.Unmarshal(pkgData, &packument)
+	return packument, err
+}
 
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			return
-		}
-	}(res.Body)
+func (NPM) GetData(url string) ([]byte, error) {
+	return getData(url)
+}
 
-	body, readErr := io.ReadAll(res.Body)
-	if readErr != nil {
-		return nil, readErr
+// SetInfoFromPackument sets the info of the version of module including its release date
+func (NPM) SetInfoFromPackument(module *model.Module, packument Packument) error {
+	version, ok := packument.Versions[module.Version]
+	if !ok {
+		return fmt.Errorf("version %s of %s not found in the registry", module.Version, packument.Name)
+	}
+	version.setInfo(module)
+	if release, ok := packument.ReleaseDate(module.Version); ok {
+		module.Info.Release = release
 	}
-	return body, nil
+	// the repository is only asked for what the registry did not have
+	version.enrich(module)
+
+	return nil
 }
 
+// SetInfoToModule sets the info from a version specific document, it does not contain the release date
 func (npm NPM) SetInfoToModule(module *model.Module, pkgData []byte) error {
 	err := json.Unmarshal(pkgData, &npm)
 	if err != nil {
-		fmt.Println("[", color.Colorize(color.Red, "Err"), "] ", err)
+		fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Red, "Err"), "] ", err)
 		return err
 	}
+	npm.setInfo(module)
+	npm.enrich(module)
+	return nil
+}
+
+func (npm NPM) setInfo(module *model.Module) {
 	module.Info.Description = npm.Description
+	module.Info.FullName = npm.Manufacturer()
+	module.Info.SPDX = npm.license()
+}
 
-	// Sometimes Author is a struct and sometimes a string => Created Owner as author string
-	// and Author as author struct
-	if authorString, ok := npm.Author.(string); ok {
-		module.Info.FullName = authorString
+// license returns the SPDX expression of the package, empty if it only refers to a license file
+func (npm NPM) license() string {
+	var types []string
+	for _, l := range append(toSlice(npm.License), toSlice(npm.Licenses)...) {
+		switch v := l.(type) {
+		case string:
+			types = append(types, v)
+		case map[string]interface{}:
+			if t, ok := v["type"].(string); ok {
+				types = append(types, t)
+			}
+		}
+	}
+	if len(types) == 0 || strings.HasPrefix(strings.ToUpper(types[0]), "SEE LICENSE IN") {
+		return ""
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+
+	return "(" + strings.Join(types, " OR ") + ")"
+}
 
+// toSlice wraps a single decoded json value into a list
+func toSlice(value interface{}) []interface{} {
+	if value == nil {
+		return nil
 	}
-	if authorStruct, ok := npm.Author.(Author); ok {
-		module.Info.FullName = authorStruct.Name
+	if values, ok := value.([]interface{}); ok {
+		return values
 	}
 
-	module.Info.SPDX = npm.License
-	//cant extract time of release of version specific package
-	//module.Info.Release =
-	return nil
+	return []interface{}{value}
 }
 
 func (NPM) CreateAPILink(packageName, version string) string {
@@ -242,52 +283,55 @@ func (NPM) CreateAPILink(packageName, version string) string {
 	return url
 }
 
+// CreatePackumentLink returns the url of the packument, the slash of scoped packages is escaped
+func (NPM) CreatePackumentLink(packageName string) string {
+	packageName = strings.ToLower(packageName)
+	packageName = strings.Replace(packageName, "/", "%2f", 1)
+	return fmt.Sprintf("https://registry.npmjs.org/%s", packageName)
+}
+
+// npmPackagePage is the prefix of the module paths of npm packages
+const npmPackagePage = "https://www.npmjs.com/package/"
+
 func (NPM) createPath(name string) string {
-	path := fmt.Sprintf("https://www.npmjs.com/package/%s", name)
-	return path
+	return npmPackagePage + name
 }
 
 func (NPM) getNameFromPath(path string) string {
-	path = path[30:]
-	return path
+	return strings.TrimPrefix(path, npmPackagePage)
 }
 
-func (npm NPM) SetParents(model *model.BuildInfo) {
-	for i := range model.Modules {
-		module := &model.Modules[i]
+// SetParents sets the parents of the modules from the dependency graph of the SBOM.
+// If the SBOM has no dependency relationships the registry dependencies are used.
+func (npm NPM) SetParents(syft *internal.Syft, info *model.BuildInfo) {
+	g := graph.FromSyft(syft)
+	if !g.HasEdges() {
+		npm.addRegistryDependencies(g, info)
+	}
+	SetGraph(info, g)
+}
+
+// addRegistryDependencies adds the dependencies of every module as listed by the registry.
+// The registry only names a version range, so all versions of a dependency in the SBOM are linked.
+func (npm NPM) addRegistryDependencies(g *graph.Graph, info *model.BuildInfo) {
+	for i := range info.Modules {
+		module := &info.Modules[i]
 		pkgNameParent := npm.getNameFromPath(module.Path)
-		fmt.Println("[", color.Colorize(color.Yellow, "Info"), "] Scanning Dependencies of:", pkgNameParent)
-		url := npm.CreateAPILink(pkgNameParent, module.Version)
-		pkgData, err := npm.GetData(url)
+		fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Yellow, "Info"), "] Scanning Dependencies of:", pkgNameParent)
+		packument, err := npm.GetPackument(pkgNameParent, true)
 		if err != nil {
 			log.Print(err)
+			markUnresolved(module, err)
+			continue
 		}
-		err = json.Unmarshal(pkgData, &npm)
-		if err != nil {
-			fmt.Println("[", color.Colorize(color.Red, "Err"), "] ", err)
-		}
-		for p := range npm.Dependencies {
-			fmt.Println("[", color.Colorize(color.Yellow, "Info"), "] Found Dependency:", p)
-			for r := range model.Modules {
-				module := &model.Modules[r]
-				pkgName := npm.getNameFromPath(module.Path)
-				if strings.Contains(p, pkgName) {
-					if !contains(module.Parents, pkgName) {
-						fmt.Println("[", color.Colorize(color.Green, "Set"), "] Set Parent:", pkgNameParent, "to:", pkgName)
-						module.Parents = append(module.Parents, pkgNameParent)
-					}
+		for p := range packument.Versions[module.Version].Dependencies {
+			fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Yellow, "Info"), "] Found Dependency:", p)
+			for _, id := range g.Lookup(p) {
+				if g.AddEdge(module.Hash, id) {
+					fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Green, "Set"), "] Set Parent:", pkgNameParent, "to:", p)
 				}
 			}
 		}
-		// for p := range npm.DevDependencies {
-		// 	for r := range model.Modules {
-		// 		module := &model.Modules[r]
-		// 		pkgName := npm.getNameFromPath(module.Path)
-		// 		if strings.Contains(p, pkgName) {
-		// 			module.Parents = append(module.Parents, pkgName)
-		// 		}
-		// 	}
-		// }
 	}
 }
 
@@ -302,21 +346,25 @@ func contains(s []string, str string) bool {
 }
 
 func (npm NPM) MakeModuleFromDependency(lib model.Dependency) model.Module {
+	name := artifactName(lib.Purl, lib.ImportName)
 	module := model.Module{
-		Name:    lib.ImportName,
-		Path:    npm.createPath(lib.ImportName),
+		Name:    name,
+		Path:    npm.createPath(name),
 		Version: lib.Version,
 		Hash:    lib.ID,
+		Purl:    lib.Purl,
+		Scope:   lib.Scope,
 	}
 	return module
 }
 
 func (npm NPM) SetRepo(module *model.Module) {
 	url := npm.CreateAPILink(module.Name, module.Version)
-	fmt.Println("[", color.Colorize(color.Yellow, "Fetch"), "] Module:", module.Name, "from:", url)
+	fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Yellow, "Fetch"), "] Module:", module.Name, "from:", url)
 	pkgData, err := npm.GetData(url)
 	if err != nil {
 		log.Print(err)
+		markUnresolved(module, err)
 	}
 	err = npm.SetInfoToModule(module, pkgData)
 	if err != nil {
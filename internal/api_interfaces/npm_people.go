@@ -0,0 +1,143 @@
+package api_interfaces
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Person is an author, contributor or maintainer of a npm package
+type Person struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	URL   string `json:"url"`
+}
+
+// sources of the manufacturer of a npm package
+const (
+	SourceAuthor       = "author"
+	SourceContributors = "contributors"
+	SourceMaintainers  = "maintainers"
+	SourcePublisher    = "publisher"
+)
+
+// ManufacturerPrecedence is the order in which the people of a package are
+// tried for the manufacturer, the first source with a name wins.
+//
+//nolint:gochecknoglobals // set by the cli
+var ManufacturerPrecedence = []string{SourceAuthor, SourceContributors, SourceMaintainers, SourcePublisher}
+
+// ParseManufacturerPrecedence parses a comma separated list of sources like "author,maintainers"
+func ParseManufacturerPrecedence(value string) ([]string, error) {
+	var sources []string
+	for _, source := range strings.Split(value, ",") {
+		source = strings.TrimSpace(source)
+		switch source {
+		case "":
+			continue
+		case SourceAuthor, SourceContributors, SourceMaintainers, SourcePublisher:
+			sources = append(sources, source)
+		default:
+			return nil, fmt.Errorf("unknown manufacturer source %q, expected one of %s, %s, %s or %s",
+				source, SourceAuthor, SourceContributors, SourceMaintainers, SourcePublisher)
+		}
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no manufacturer source given")
+	}
+
+	return sources, nil
+}
+
+// "Name <email> (url)", email and url are optional
+var personRegEx = regexp.MustCompile(`^([^<(]*)(?:<([^>]*)>)?\s*(?:\(([^)]*)\))?`)
+
+// ParsePerson parses the string shorthand "Name <email> (url)" of package.json
+func ParsePerson(value string) Person {
+	match := personRegEx.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return Person{Name: strings.TrimSpace(value)}
+	}
+
+	return Person{
+		Name:  strings.TrimSpace(match[1]),
+		Email: strings.TrimSpace(match[2]),
+		URL:   strings.TrimSpace(match[3]),
+	}
+}
+
+// toPerson converts a decoded json value, either the string shorthand or an object
+func toPerson(value interface{}) (Person, bool) {
+	switch v := value.(type) {
+	case string:
+		p := ParsePerson(v)
+		return p, p.Name != "" || p.Email != ""
+	case map[string]interface{}:
+		var p Person
+		p.Name, _ = v["name"].(string)
+		p.Email, _ = v["email"].(string)
+		p.URL, _ = v["url"].(string)
+		p.Name = strings.TrimSpace(p.Name)
+		return p, p.Name != "" || p.Email != ""
+	}
+
+	return Person{}, false
+}
+
+// toPeople converts a decoded json value holding one person or a list of them
+func toPeople(value interface{}) []Person {
+	var people []Person
+	for _, v := range toSlice(value) {
+		if p, ok := toPerson(v); ok {
+			people = append(people, p)
+		}
+	}
+
+	return people
+}
+
+// String returns the name of the person, the email if it has none
+func (p Person) String() string {
+	if p.Name != "" {
+		return p.Name
+	}
+
+	return p.Email
+}
+
+// people returns the persons of the package named by source
+func (npm NPM) people(source string) []Person {
+	switch source {
+	case SourceAuthor:
+		return toPeople(npm.Author)
+	case SourceContributors:
+		return toPeople(npm.Contributors)
+	case SourceMaintainers:
+		return toPeople(npm.Maintainers)
+	case SourcePublisher:
+		return toPeople(npm.NpmUser)
+	}
+
+	return nil
+}
+
+// Manufacturer returns the people of the first source of ManufacturerPrecedence naming anyone
+func (npm NPM) Manufacturer() string {
+	for _, source := range ManufacturerPrecedence {
+		people := npm.people(source)
+		if len(people) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(people))
+		for _, p := range people {
+			if !contains(names, p.String()) {
+				names = append(names, p.String())
+			}
+		}
+
+		return strings.Join(names, ", ")
+	}
+
+	return ""
+}
@@ -0,0 +1,85 @@
+package api_interfaces
+
+import (
+	"log"
+	"path"
+	"strings"
+	"syfttoymlconverter/internal/model"
+	"syfttoymlconverter/internal/provider"
+)
+
+// repository returns the normalized repository field of the package
+func (npm NPM) repository() (provider.Repository, bool) {
+	var raw, directory string
+	switch v := npm.Repository.(type) {
+	case string:
+		raw = v
+	case map[string]interface{}:
+		raw, _ = v["url"].(string)
+		directory, _ = v["directory"].(string)
+	}
+
+	repo, ok := provider.NormalizeRepoURL(raw)
+	if ok && directory != "" {
+		repo.Directory = strings.Trim(directory, "/")
+	}
+
+	return repo, ok
+}
+
+// releaseTags returns the tags a version of a npm package is commonly released as,
+// monorepos tag every package as name@version or by the directory of the package
+// as <dir>@version or <dir>/v<version>
+func releaseTags(name, version, directory string) []string {
+	tags := []string{"v" + version, version, name + "@" + version}
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		tags = append(tags, name[i+1:]+"@"+version)
+	}
+	if directory != "" {
+		if base := path.Base(directory); base != name && !strings.HasSuffix(name, "/"+base) {
+			tags = append(tags, base+"@"+version)
+		}
+		tags = append(tags, directory+"/v"+version)
+	}
+
+	return tags
+}
+
+// enrich fills the info the registry is missing from the source code repository of the package
+func (npm NPM) enrich(module *model.Module) {
+	repo, ok := npm.repository()
+	if !ok {
+		return
+	}
+	module.Repository = repo.Path
+
+	info := &module.Info
+	if info.SPDX != "" && info.Description != "" && info.FullName != "" && !info.Release.IsZero() {
+		return
+	}
+
+	// the tags are only needed for the release date, a known one saves their lookups
+	var tags []string
+	if info.Release.IsZero() {
+		tags = releaseTags(npm.Name, module.Version, repo.Directory)
+	}
+
+	repoInfo, err := provider.FetchRepositoryInfo(repo.Path, tags)
+	if err != nil {
+		log.Print(err)
+		markUnresolved(module, err)
+	}
+
+	if info.SPDX == "" {
+		info.SPDX = repoInfo.SPDX
+	}
+	if info.Description == "" {
+		info.Description = repoInfo.Description
+	}
+	if info.FullName == "" {
+		info.FullName = repoInfo.FullName
+	}
+	if info.Release.IsZero() {
+		info.Release = repoInfo.Release
+	}
+}
@@ -3,11 +3,11 @@ package api_interfaces
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"os"
 	"strings"
 	"syfttoymlconverter/internal"
+	"syfttoymlconverter/internal/graph"
 	"syfttoymlconverter/internal/model"
 	"time"
 
@@ -15,10 +15,13 @@ import (
 )
 
 type Module struct {
-	Path    string // Import path, such as "github.com/mitchellh/golicense"
-	SubPath string // matches the trailing import version specifiers like `/v12`
-	Version string // Version like "v1.2.3"
-	Hash    string // Hash such as "h1:abcd1234"
+	Path    string            // Import path, such as "github.com/mitchellh/golicense"
+	SubPath string            // matches the trailing import version specifiers like `/v12`
+	Version string            // Version like "v1.2.3"
+	Hash    string            // Hash such as "h1:abcd1234"
+	Purl    string            // Package url such as "pkg:nuget/Newtonsoft.Json@13.0.1"
+	Scope   string            // Dependency scope like "dev" if the SBOM records it
+	Hashes  map[string]string // Checksums by algorithm such as "SHA-512"
 }
 
 // Structure of NUGET API Call https://api.nuget.org/v3/registration5-semver1/{PackageNameLowerCase}/index.json
@@ -86,7 +89,7 @@ func (Nuget) ParseEmbeddedModules(syft *internal.Syft) (model.BuildInfo, error)
 	}
 
 	return model.BuildInfo{
-		Path:    syft.Artifacts[0].Locations[0].Path,
+		Path:    syft.Path(),
 		Mod:     "Mod",
 		Modules: Nuget.toModule(Nuget{}, modules),
 	}, nil
@@ -95,13 +98,16 @@ func (Nuget) ParseEmbeddedModules(syft *internal.Syft) (model.BuildInfo, error)
 func (Nuget) SyftToModule(syft *internal.Syft) ([]Module, error) {
 	var result []Module
 	for _, data := range syft.Artifacts {
-		data.Name, _ = Nuget.createPath(Nuget{}, data.Name)
+		data.Name, _ = Nuget.createPath(Nuget{}, artifactName(data.Purl, data.Name))
 		next := Module{
 			Path: data.Name,
+			Purl: data.Purl,
 			//TODO: subpath is maybe not needed
-			SubPath: data.Version[:strings.Index(data.Version, ".")],
+			SubPath: majorVersion(data.Version),
 			Version: data.Version,
 			Hash:    data.ID,
+			Scope:   data.Scope,
+			Hashes:  hashes(data),
 		}
 
 		result = append(result, next)
@@ -119,6 +125,9 @@ func (Nuget) toModule(modules []Module) []model.Module {
 			SubPath: m.SubPath,
 			Version: m.Version,
 			Hash:    m.Hash,
+			Purl:    m.Purl,
+			Scope:   m.Scope,
+			Hashes:  m.Hashes,
 		})
 	}
 
@@ -128,46 +137,26 @@ func (Nuget) toModule(modules []Module) []model.Module {
 func (nuget Nuget) SetRepoInfo(syft *internal.Syft, info *model.BuildInfo) {
 	for i := range info.Modules {
 		module := &info.Modules[i]
-		url := nuget.CreateAPILink(syft.Artifacts[i].Name)
-		fmt.Println("[", color.Colorize(color.Yellow, "Fetch"), "] Module: ", module.Path, "from: ", url)
+		url := nuget.CreateAPILink(nuget.getNameFromPath(module.Path))
+		fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Yellow, "Fetch"), "] Module: ", module.Path, "from: ", url)
 		pkgData, err := nuget.GetData(url)
 		if err != nil {
 			log.Print(err)
+			markUnresolved(module, err)
 		}
 		nuget.SetInfoToModule(module, pkgData)
 	}
-	fmt.Println("[", color.Colorize(color.Green, "Succ"), "] All Modules were parsed ")
+	fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Green, "Succ"), "] All Modules were parsed ")
 }
 
 func (Nuget) GetData(url string) ([]byte, error) {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	res, err := http.DefaultClient.Do(req)
-	if err != nil {
-
-		return nil, err
-	}
-
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			return
-		}
-	}(res.Body)
-
-	body, readErr := io.ReadAll(res.Body)
-	if readErr != nil {
-		return nil, readErr
-	}
-	return body, nil
+	return getData(url)
 }
 
 func (api Nuget) SetInfoToModule(module *model.Module, pkgData []byte) {
 	err := json.Unmarshal(pkgData, &api)
 	if err != nil {
-		fmt.Println("[", color.Colorize(color.Red, "Err"), "] ", err)
+		fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Red, "Err"), "] ", err)
 	}
 
 	//double iteration because big dependencies have multiple "sites"
@@ -198,35 +187,43 @@ func (api Nuget) SetInfoToModule(module *model.Module, pkgData []byte) {
 	}
 }
 
-func (nuget Nuget) SetParents(model *model.BuildInfo) {
-	for i := range model.Modules {
-		module := &model.Modules[i]
-		pkgPaths := strings.Split(module.Path, "/")
-		pkgNameParent := pkgPaths[len(pkgPaths)-1]
-		url := nuget.CreateAPILink(pkgNameParent)
+// SetParents sets the parents of the modules from the dependency graph of the SBOM.
+// If the SBOM has no dependency relationships the registry dependencies are used.
+func (nuget Nuget) SetParents(syft *internal.Syft, info *model.BuildInfo) {
+	g := graph.FromSyft(syft)
+	if !g.HasEdges() {
+		nuget.addRegistryDependencies(g, info)
+	}
+	SetGraph(info, g)
+}
+
+// addRegistryDependencies adds the dependencies of every module as listed by the registry
+// for all target frameworks. All versions of a dependency in the SBOM are linked.
+func (nuget Nuget) addRegistryDependencies(g *graph.Graph, info *model.BuildInfo) {
+	for i := range info.Modules {
+		module := &info.Modules[i]
+		url := nuget.CreateAPILink(nuget.getNameFromPath(module.Path))
 		pkgData, err := nuget.GetData(url)
 		if err != nil {
 			log.Print(err)
+			markUnresolved(module, err)
+			continue
 		}
-		err = json.Unmarshal(pkgData, &nuget)
+		var registration Nuget
+		err = json.Unmarshal(pkgData, &registration)
 		if err != nil {
-			fmt.Println("[", color.Colorize(color.Red, "Err"), "] ", err)
+			fmt.Fprintln(os.Stderr, "[", color.Colorize(color.Red, "Err"), "] ", err)
+			continue
 		}
-		for _, item := range nuget.Items {
+		for _, item := range registration.Items {
 			for _, data := range item.Items {
-				if module.Version == data.CatalogEntry.Version {
-					for _, p := range data.CatalogEntry.DependencyGroups {
-						for _, d := range p.Dependencies {
-							for r := range model.Modules {
-								module := &model.Modules[r]
-								pkgPaths := strings.Split(module.Path, "/")
-								pkgName := pkgPaths[len(pkgPaths)-1]
-								if strings.Contains(d.ID, pkgName) {
-									if !contains(module.Parents, pkgNameParent) {
-										module.Parents = append(module.Parents, pkgNameParent)
-									}
-								}
-							}
+				if module.Version != data.CatalogEntry.Version {
+					continue
+				}
+				for _, p := range data.CatalogEntry.DependencyGroups {
+					for _, d := range p.Dependencies {
+						for _, id := range g.Lookup(d.ID) {
+							g.AddEdge(module.Hash, id)
 						}
 					}
 				}
@@ -241,7 +238,14 @@ func (Nuget) CreateAPILink(packageName string) string {
 	return url
 }
 
+// nugetPackagePage is the prefix of the module paths of nuget packages
+const nugetPackagePage = "https://www.nuget.org/packages/"
+
 func (Nuget) createPath(name string) (string, error) {
-	path := fmt.Sprintf("https://www.nuget.org/packages/%s", name)
+	path := nugetPackagePage + name
 	return path, nil
 }
+
+func (Nuget) getNameFromPath(path string) string {
+	return strings.TrimPrefix(path, nugetPackagePage)
+}
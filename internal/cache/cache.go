@@ -0,0 +1,181 @@
+// Package cache stores the documents fetched from registries and forges on disk,
+// so repeated runs do not download the same metadata again.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultTTL is the age after which an entry is fetched again
+const DefaultTTL = 24 * time.Hour
+
+// Cache is a content addressed store: every entry is saved under the sha256 of its key
+// (usually the request url). Entries are additionally kept in memory for the current run,
+// so documents fetched twice within one run are only downloaded once even without disk cache.
+type Cache struct {
+	Dir string
+	TTL time.Duration
+	// Refresh ignores existing disk entries but still stores the new ones
+	Refresh bool
+	// Disabled turns off the disk cache, the in-memory cache of the run stays active
+	Disabled bool
+	// Offline serves every request from Dir, usually a mirror written by an online run,
+	// and never touches the network. Missing entries fail with ErrOffline.
+	Offline bool
+
+	mem sync.Map
+}
+
+//nolint:gochecknoglobals // shared by the api interfaces and providers, configured by the cli
+var Default = New(DefaultDir(), DefaultTTL)
+
+// ErrOffline is returned in offline mode for documents missing in the mirror
+var ErrOffline = errors.New("not available offline")
+
+// NewMirror opens the mirror in dir. An online mirror (re)downloads every document
+// and stores it in dir, an offline mirror serves every document from dir.
+func NewMirror(dir string, offline bool) *Cache {
+	return &Cache{Dir: dir, Refresh: !offline, Offline: offline}
+}
+
+// MissingError is returned for a key missing in offline mode, it matches ErrOffline
+type MissingError struct {
+	Key string
+}
+
+func (e *MissingError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, ErrOffline)
+}
+
+func (e *MissingError) Is(target error) bool {
+	return target == ErrOffline
+}
+
+func New(dir string, ttl time.Duration) *Cache {
+	return &Cache{Dir: dir, TTL: ttl}
+}
+
+// DefaultDir is the cache directory of this tool, e.g. ~/.cache/syft2yml on linux
+func DefaultDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "syft2yml")
+}
+
+// Get returns the entry of key if it exists and is younger than the TTL
+func (c *Cache) Get(key string) ([]byte, bool) {
+	if data, ok := c.mem.Load(key); ok {
+		return data.([]byte), true
+	}
+	if (c.Disabled || c.Refresh || c.Dir == "") && !c.Offline {
+		return nil, false
+	}
+
+	path := c.path(key)
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, false
+	}
+	if c.TTL > 0 && !c.Offline && time.Since(stat.ModTime()) > c.TTL {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to read cache entry of %s", key)
+
+		return nil, false
+	}
+
+	c.mem.Store(key, data)
+
+	return data, true
+}
+
+// Put stores data under key
+func (c *Cache) Put(key string, data []byte) {
+	c.mem.Store(key, data)
+	if c.Disabled || c.Dir == "" {
+		return
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		log.Debug().Err(err).Msgf("Failed to create cache directory for %s", key)
+
+		return
+	}
+
+	// write to a temporary file first, concurrent readers never see half written entries
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to write cache entry of %s", key)
+
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		log.Debug().Err(err).Msgf("Failed to write cache entry of %s", key)
+	}
+}
+
+// Prune removes all disk entries older than maxAge, 0 removes everything.
+// It returns the number of removed entries.
+func (c *Cache) Prune(maxAge time.Duration) (int, error) {
+	removed := 0
+	err := filepath.WalkDir(c.Dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if maxAge > 0 && time.Since(info.ModTime()) <= maxAge {
+			return nil
+		}
+
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+
+		return nil
+	})
+
+	return removed, err
+}
+
+// path returns the file of key: <dir>/<first two hex chars>/<sha256>
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+
+	return filepath.Join(c.Dir, name[:2], name)
+}
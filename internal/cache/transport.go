@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// Transport caches successful and not found GET responses of an http client,
+// it is used for the forge api clients
+type Transport struct {
+	Cache *Cache
+	Base  http.RoundTripper
+	// Credential is the fingerprint of the token Base authenticates with, empty
+	// for anonymous requests. It is part of the keys, so responses to authenticated
+	// requests, which may contain private repositories, are only served to runs
+	// with the same token.
+	Credential string
+}
+
+// Transport wraps base, a nil base uses http.DefaultTransport. token is the
+// credential base sends, if any.
+func (c *Cache) Transport(base http.RoundTripper, token string) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{Cache: c, Base: base, Credential: fingerprint(token)}
+}
+
+// fingerprint returns a short hash of token, so the token itself is not written
+// to the keys of the cache or a mirror
+func fingerprint(token string) string {
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.Base.RoundTrip(req)
+	}
+
+	key := Key(req.URL.String(), req.Header.Get("Accept"))
+	if t.Credential != "" {
+		key += "\ncredential " + t.Credential
+	}
+	if data, ok := t.Cache.Get(key); ok {
+		if res, err := decodeResponse(req, data); err == nil {
+			return res, nil
+		}
+	}
+	if t.Cache.Offline {
+		return nil, &MissingError{Key: req.URL.String()}
+	}
+
+	res, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNotFound {
+		return res, nil
+	}
+
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.Cache.Put(key, encodeResponse(res.StatusCode, body))
+
+	return res, nil
+}
+
+// Key builds the cache key of a request, the accept header selects between
+// different representations of the same url
+func Key(url, accept string) string {
+	if accept == "" {
+		return url
+	}
+
+	return url + "\n" + accept
+}
+
+// entries of the transport are stored as "<status code>\n<body>"
+func encodeResponse(status int, body []byte) []byte {
+	return append([]byte(strconv.Itoa(status)+"\n"), body...)
+}
+
+func decodeResponse(req *http.Request, data []byte) (*http.Response, error) {
+	i := bytes.IndexByte(data, '\n')
+	if i < 0 {
+		return nil, errors.New("malformed cache entry")
+	}
+	code, err := strconv.Atoi(string(data[:i]))
+	if err != nil {
+		return nil, err
+	}
+	body := data[i+1:]
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
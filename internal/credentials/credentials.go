@@ -0,0 +1,77 @@
+// Package credentials resolves the access tokens used for the source code hosts
+// (github.com, GitHub Enterprise, GitLab, ...) the providers talk to.
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Source looks up the token of a host like github.com
+type Source interface {
+	// Token returns the token for host and whether one was found
+	Token(host string) (string, bool)
+	// Name describes the source in log messages
+	Name() string
+}
+
+// Chain asks every source in order and returns the first token found
+type Chain []Source
+
+func (c Chain) Token(host string) (string, bool) {
+	for _, s := range c {
+		if token, ok := s.Token(host); ok {
+			log.Debug().Msgf("Using token for %s from %s", host, s.Name())
+
+			return token, true
+		}
+	}
+
+	return "", false
+}
+
+func (Chain) Name() string {
+	return "credential chain"
+}
+
+// Default returns the lookup order used by the cli: environment, the hosts file
+// of this tool, ~/.netrc and the hosts file of the gh cli.
+// An empty hostsFile uses DefaultHostsFile.
+func Default(hostsFile string) Chain {
+	if hostsFile == "" {
+		hostsFile = DefaultHostsFile()
+	}
+
+	home, _ := os.UserHomeDir()
+
+	return Chain{
+		Env{},
+		File{Path: hostsFile},
+		Netrc{Path: filepath.Join(home, ".netrc")},
+		GhHosts{Path: ghHostsFile(home)},
+	}
+}
+
+// DefaultHostsFile is the location of the hosts file of this tool,
+// e.g. ~/.config/syft2yml/hosts.yml on linux
+func DefaultHostsFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "syft2yml", "hosts.yml")
+}
+
+func ghHostsFile(home string) string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
@@ -0,0 +1,26 @@
+package credentials
+
+import "os"
+
+// envVars lists the environment variables checked per host, in order
+var envVars = map[string][]string{
+	"github.com": {"GITHUB_TOKEN", "GH_TOKEN"},
+	"gitlab.com": {"GITLAB_TOKEN"},
+}
+
+// Env reads tokens from well known environment variables
+type Env struct{}
+
+func (Env) Token(host string) (string, bool) {
+	for _, name := range envVars[host] {
+		if token := os.Getenv(name); token != "" {
+			return token, true
+		}
+	}
+
+	return "", false
+}
+
+func (Env) Name() string {
+	return "environment"
+}
@@ -0,0 +1,62 @@
+package credentials
+
+import (
+	"os"
+
+	"github.com/goccy/go-yaml"
+	"github.com/rs/zerolog/log"
+)
+
+// File reads tokens from the hosts file of this tool:
+//
+//	hosts:
+//	  github.com:
+//	    token: ghp_...
+type File struct {
+	Path string
+}
+
+type hostsFile struct {
+	Hosts map[string]struct {
+		Token string `yaml:"token"`
+	} `yaml:"hosts"`
+}
+
+func (f File) Token(host string) (string, bool) {
+	var hosts hostsFile
+	if !readYaml(f.Path, &hosts) {
+		return "", false
+	}
+
+	token := hosts.Hosts[host].Token
+
+	return token, token != ""
+}
+
+func (f File) Name() string {
+	return f.Path
+}
+
+// readYaml decodes the file at path into v, a missing file is not an error
+func readYaml(path string, v interface{}) bool {
+	if path == "" {
+		return false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn().Err(err).Msgf("Failed to read %s", path)
+		}
+
+		return false
+	}
+
+	if err := yaml.Unmarshal(data, v); err != nil {
+		log.Warn().Err(err).Msgf("Failed to parse %s", path)
+
+		return false
+	}
+
+	return true
+}
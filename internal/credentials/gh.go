@@ -0,0 +1,27 @@
+package credentials
+
+// GhHosts reads the tokens stored by the gh cli in its hosts.yml:
+//
+//	github.com:
+//	  user: octocat
+//	  oauth_token: gho_...
+type GhHosts struct {
+	Path string
+}
+
+func (g GhHosts) Token(host string) (string, bool) {
+	var hosts map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	}
+	if !readYaml(g.Path, &hosts) {
+		return "", false
+	}
+
+	token := hosts[host].OAuthToken
+
+	return token, token != ""
+}
+
+func (g GhHosts) Name() string {
+	return g.Path
+}
@@ -0,0 +1,64 @@
+package credentials
+
+import (
+	"os"
+	"strings"
+)
+
+// Netrc reads the password of a machine entry in a .netrc file.
+// For a host the entries "<host>" and "api.<host>" are checked.
+type Netrc struct {
+	Path string
+}
+
+func (n Netrc) Token(host string) (string, bool) {
+	data, err := os.ReadFile(n.Path)
+	if err != nil {
+		return "", false
+	}
+
+	machines := parseNetrc(string(data))
+	for _, name := range []string{host, "api." + host} {
+		if token, ok := machines[name]; ok && token != "" {
+			return token, true
+		}
+	}
+
+	return "", false
+}
+
+func (n Netrc) Name() string {
+	return n.Path
+}
+
+// parseNetrc returns the password of every machine, macros are skipped
+func parseNetrc(data string) map[string]string {
+	machines := map[string]string{}
+
+	var machine string
+	fields := strings.Fields(data)
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				machine = fields[i]
+			}
+		case "default":
+			machine = ""
+		case "password":
+			if i+1 < len(fields) {
+				i++
+				if machine != "" {
+					machines[machine] = fields[i]
+				}
+			}
+		case "macdef":
+			// a macro runs until the next empty line, which Fields does not preserve,
+			// so stop parsing entirely like most netrc readers do
+			return machines
+		}
+	}
+
+	return machines
+}
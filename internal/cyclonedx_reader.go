@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"syfttoymlconverter/internal/purl"
+)
+
+// relationship types used for converted SBOMs, as written by syft
+const (
+	relationshipContains     = "contains"
+	relationshipDependencyOf = "dependency-of"
+)
+
+// cyclonedxBom is the subset of a CycloneDX 1.4/1.5 BOM the converter needs.
+// The json and xml encodings share it.
+type cyclonedxBom struct {
+	BomFormat    string                `json:"bomFormat" xml:"-"`
+	SpecVersion  string                `json:"specVersion" xml:"-"`
+	SerialNumber string                `json:"serialNumber" xml:"serialNumber,attr"`
+	Metadata     cyclonedxMetadata     `json:"metadata" xml:"metadata"`
+	Components   []cyclonedxComponent  `json:"components" xml:"components>component"`
+	Dependencies []cyclonedxDependency `json:"dependencies" xml:"dependencies>dependency"`
+	// XMLName carries the namespace which holds the spec version in xml
+	XMLName xml.Name `json:"-" xml:"bom"`
+}
+
+type cyclonedxMetadata struct {
+	Component *cyclonedxComponent `json:"component" xml:"component"`
+}
+
+type cyclonedxComponent struct {
+	BomRef     string               `json:"bom-ref" xml:"bom-ref,attr"`
+	Type       string               `json:"type" xml:"type,attr"`
+	Group      string               `json:"group" xml:"group"`
+	Name       string               `json:"name" xml:"name"`
+	Version    string               `json:"version" xml:"version"`
+	Scope      string               `json:"scope" xml:"scope"`
+	Purl       string               `json:"purl" xml:"purl"`
+	Cpe        string               `json:"cpe" xml:"cpe"`
+	Licenses   []cyclonedxLicense   `json:"licenses" xml:"licenses>license"`
+	Expression []string             `json:"-" xml:"licenses>expression"`
+	Hashes     []cyclonedxHash      `json:"hashes" xml:"hashes>hash"`
+	Components []cyclonedxComponent `json:"components" xml:"components>component"`
+}
+
+// cyclonedxLicense is a license choice: in json either {"license": {...}} or
+// {"expression": "..."}, in xml the <license> element itself
+type cyclonedxLicense struct {
+	License *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"license" xml:"-"`
+	Expression string `json:"expression" xml:"-"`
+
+	ID   string `json:"-" xml:"id"`
+	Name string `json:"-" xml:"name"`
+}
+
+type cyclonedxHash struct {
+	Alg     string `json:"alg" xml:"alg,attr"`
+	Content string `json:"content" xml:",chardata"`
+}
+
+type cyclonedxDependency struct {
+	Ref       string   `json:"ref" xml:"ref,attr"`
+	DependsOn []string `json:"dependsOn" xml:"-"`
+	// in xml the dependencies are nested dependency elements
+	Nested []cyclonedxDependency `json:"-" xml:"dependency"`
+}
+
+// ReadCycloneDXJSON converts a CycloneDX json BOM
+func ReadCycloneDXJSON(data []byte) (*Syft, error) {
+	var bom cyclonedxBom
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, err
+	}
+
+	return bom.toSyft(), nil
+}
+
+// ReadCycloneDXXML converts a CycloneDX xml BOM
+func ReadCycloneDXXML(data []byte) (*Syft, error) {
+	var bom cyclonedxBom
+	if err := xml.Unmarshal(data, &bom); err != nil {
+		return nil, err
+	}
+
+	// the spec version is only part of the namespace: http://cyclonedx.org/schema/bom/1.5
+	bom.SpecVersion = bom.XMLName.Space[strings.LastIndex(bom.XMLName.Space, "/")+1:]
+	for i := range bom.Dependencies {
+		bom.Dependencies[i].flattenXML()
+	}
+
+	return bom.toSyft(), nil
+}
+
+func (d *cyclonedxDependency) flattenXML() {
+	for _, nested := range d.Nested {
+		d.DependsOn = append(d.DependsOn, nested.Ref)
+	}
+}
+
+// toSyft maps the components to artifacts, the metadata component to the source and
+// the dependencies to dependency-of relationships
+func (bom cyclonedxBom) toSyft() *Syft {
+	syft := &Syft{
+		Schema: Schema{Version: bom.SpecVersion, URL: "https://cyclonedx.org/schema/bom/" + bom.SpecVersion},
+		Source: Source{ID: bom.SerialNumber, Type: "cyclonedx"},
+	}
+	if root := bom.Metadata.Component; root != nil {
+		syft.Source.Target = root.fullName()
+		if root.BomRef != "" {
+			syft.Source.ID = root.BomRef
+		}
+	}
+
+	var add func(components []cyclonedxComponent)
+	add = func(components []cyclonedxComponent) {
+		for _, c := range components {
+			a := c.toArtifact()
+			syft.Artifacts = append(syft.Artifacts, a)
+			syft.ArtifactRelationships = append(syft.ArtifactRelationships, Relationship{
+				Parent: syft.Source.ID,
+				Child:  a.ID,
+				Type:   relationshipContains,
+			})
+			add(c.Components)
+		}
+	}
+	add(bom.Components)
+
+	for _, d := range bom.Dependencies {
+		for _, dependency := range d.DependsOn {
+			syft.ArtifactRelationships = append(syft.ArtifactRelationships, Relationship{
+				Parent: dependency,
+				Child:  d.Ref,
+				Type:   relationshipDependencyOf,
+			})
+		}
+	}
+
+	return syft
+}
+
+func (c cyclonedxComponent) toArtifact() Artifact {
+	a := Artifact{
+		ID:      c.BomRef,
+		Name:    c.fullName(),
+		Version: c.Version,
+		Type:    c.Type,
+		Purl:    c.Purl,
+		Scope:   cyclonedxScope(c.Scope),
+	}
+	if a.ID == "" {
+		a.ID = c.Purl
+	}
+	if c.Cpe != "" {
+		a.Cpes = []string{c.Cpe}
+	}
+
+	for _, l := range c.Licenses {
+		switch {
+		case l.License != nil && l.License.ID != "":
+			a.Licenses = append(a.Licenses, l.License.ID)
+		case l.License != nil && l.License.Name != "":
+			a.Licenses = append(a.Licenses, l.License.Name)
+		case l.Expression != "":
+			a.Licenses = append(a.Licenses, l.Expression)
+		case l.ID != "":
+			a.Licenses = append(a.Licenses, l.ID)
+		case l.Name != "":
+			a.Licenses = append(a.Licenses, l.Name)
+		}
+	}
+	a.Licenses = append(a.Licenses, c.Expression...)
+
+	for _, h := range c.Hashes {
+		a.Digests = append(a.Digests, Digest{Algorithm: h.Alg, Value: strings.TrimSpace(h.Content)})
+	}
+
+	return a
+}
+
+// cyclonedxScope maps the scope of a component to the dependency scope. Required
+// components are runtime dependencies which have no scope, like in SPDX.
+func cyclonedxScope(scope string) string {
+	switch scope {
+	case "optional", "excluded":
+		return scope
+	}
+
+	return ""
+}
+
+// fullName returns the name as the ecosystem writes it, e.g. @angular/core
+func (c cyclonedxComponent) fullName() string {
+	if p, err := purl.Parse(c.Purl); err == nil {
+		return p.FullName()
+	}
+	if c.Group != "" {
+		return c.Group + "/" + c.Name
+	}
+
+	return c.Name
+}
@@ -0,0 +1,145 @@
+// Package document assembles the complete OTS document from the project
+// configuration, the previous document and the generated libraries
+package document
+
+import (
+	"fmt"
+	"os"
+	"syfttoymlconverter/internal/model"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Project is the project configuration file, the parts of the document that do
+// not come from the SBOM:
+//
+//	header:
+//	  title: Off-The-Shelf Software
+//	  documentName: Test Document
+//	  documentNumber: "00001"
+//	  docversion: 1.0.0
+//	frontPage:
+//	  creator: {name: ..., profession: ...}
+//	  reviewers: [{reviewer: {name: ..., profession: ...}}]
+//	  approver: {name: ..., profession: ...}
+//	referenceDocuments:
+//	  reference: "[PDP]"
+//	  description: Project Data Plan
+//	  documentNumber: "324"
+//	history:
+//	  reason: Update of the OTS list
+type Project struct {
+	Header              model.Header              `yaml:"header"`
+	FrontPage           model.FrontPage           `yaml:"frontPage"`
+	ReferencedDocuments model.ReferencedDocuments `yaml:"referenceDocuments"`
+	History             HistoryConfig             `yaml:"history"`
+}
+
+// HistoryConfig sets the history entry appended by every run
+type HistoryConfig struct {
+	// Version defaults to the docversion of the header
+	Version string `yaml:"version"`
+	// Reason defaults to a note naming the scanned target
+	Reason string `yaml:"reason"`
+	// Disabled stops appending entries
+	Disabled bool `yaml:"disabled"`
+}
+
+// LoadProject reads a project configuration file
+func LoadProject(path string) (Project, error) {
+	var project Project
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return project, err
+	}
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return project, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return project, nil
+}
+
+// Read reads an existing document, ok is false if the file does not exist.
+// A bare library list is read as document without header.
+func Read(path string) (model.Document, bool, error) {
+	var doc model.Document
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return doc, false, nil
+	}
+	if err != nil {
+		return doc, false, err
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return doc, false, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return doc, true, nil
+}
+
+// Build returns the document with the libraries. Header, front page and referenced
+// documents come from the project, if it is nil from the previous document. The
+// histories of the previous document are continued and the entry of this run is
+// appended, target is the scanned project named by the default reason.
+func Build(project *Project, previous *model.Document, libraries []model.Library, target string, now time.Time) model.Document {
+	var doc model.Document
+
+	switch {
+	case project != nil:
+		doc.Header = project.Header
+		doc.FrontPage = project.FrontPage
+		doc.ReferencedDocuments = project.ReferencedDocuments
+	case previous != nil:
+		doc.Header = previous.Header
+		doc.FrontPage = previous.FrontPage
+		doc.ReferencedDocuments = previous.ReferencedDocuments
+	}
+
+	if previous != nil && len(previous.FrontPage.Histories) > 0 {
+		doc.FrontPage.Histories = append([]model.History{}, previous.FrontPage.Histories...)
+	}
+
+	var config HistoryConfig
+	if project != nil {
+		config = project.History
+	}
+	if !config.Disabled {
+		doc.FrontPage.Histories = appendHistory(doc.FrontPage.Histories, historyEntry(config, doc.Header, target, now))
+	}
+
+	doc.Libraries = libraries
+	if doc.Libraries == nil {
+		doc.Libraries = []model.Library{}
+	}
+
+	return doc
+}
+
+func historyEntry(config HistoryConfig, header model.Header, target string, now time.Time) model.HistoryEntry {
+	entry := model.HistoryEntry{
+		Version:                config.Version,
+		BeginOfValidation:      now.Format("2006-01-02"),
+		ReasonAndContentColumn: config.Reason,
+	}
+	if entry.Version == "" {
+		entry.Version = header.DocVersion
+	}
+	if entry.ReasonAndContentColumn == "" {
+		entry.ReasonAndContentColumn = fmt.Sprintf("OTS libraries generated from %s", target)
+	}
+
+	return entry
+}
+
+// appendHistory appends entry unless the last entry is the same, so repeated runs
+// on one day do not add an entry each
+func appendHistory(histories []model.History, entry model.HistoryEntry) []model.History {
+	if n := len(histories); n > 0 && histories[n-1].History == entry {
+		return histories
+	}
+
+	return append(histories, model.History{History: entry})
+}
@@ -0,0 +1,309 @@
+// Package export writes the enriched modules as SBOM for the consumers that do
+// not read foss.yml, like regulators and customers
+package export
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"syfttoymlconverter/internal/model"
+	"syfttoymlconverter/internal/purl"
+	"time"
+)
+
+// CycloneDXVersion is the spec version of the written BOMs
+const CycloneDXVersion = "1.5"
+
+// toolName names the converter in the metadata of the written SBOMs
+const toolName = "syft2yml"
+
+// BOM is a CycloneDX json BOM
+type BOM struct {
+	BomFormat    string       `json:"bomFormat"`
+	SpecVersion  string       `json:"specVersion"`
+	SerialNumber string       `json:"serialNumber"`
+	Version      int          `json:"version"`
+	Metadata     Metadata     `json:"metadata"`
+	Components   []Component  `json:"components"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// Metadata describes the BOM and the scanned project
+type Metadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     struct {
+		Components []Component `json:"components"`
+	} `json:"tools"`
+	Component *Component `json:"component,omitempty"`
+}
+
+// Component is a package of the BOM. CycloneDX 1.5 has no manufacturer of a
+// component, the manufacturer of the library table is written as supplier.
+type Component struct {
+	Type               string              `json:"type"`
+	BomRef             string              `json:"bom-ref,omitempty"`
+	Supplier           *Entity             `json:"supplier,omitempty"`
+	Group              string              `json:"group,omitempty"`
+	Name               string              `json:"name"`
+	Version            string              `json:"version,omitempty"`
+	Description        string              `json:"description,omitempty"`
+	Scope              string              `json:"scope,omitempty"`
+	Hashes             []Hash              `json:"hashes,omitempty"`
+	Licenses           []License           `json:"licenses,omitempty"`
+	Purl               string              `json:"purl,omitempty"`
+	ExternalReferences []ExternalReference `json:"externalReferences,omitempty"`
+	Properties         []Property          `json:"properties,omitempty"`
+}
+
+// Entity is an organization or person
+type Entity struct {
+	Name string   `json:"name"`
+	URL  []string `json:"url,omitempty"`
+}
+
+// Hash is a checksum of a component
+type Hash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// License is a license choice, either an SPDX id or an expression
+type License struct {
+	License *struct {
+		ID string `json:"id"`
+	} `json:"license,omitempty"`
+	Expression string `json:"expression,omitempty"`
+}
+
+// ExternalReference links the source code or the registry page
+type ExternalReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Property holds values CycloneDX has no field for, like the release date
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Dependency lists the components ref directly depends on
+type Dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// spdxID matches a single license id, everything else is written as expression
+var spdxID = regexp.MustCompile(`^[A-Za-z0-9.+-]+$`) //nolint:gochecknoglobals // compiled once
+
+// CycloneDX returns the BOM of the modules. The project named by info.Path is the
+// metadata component and depends on the direct modules, the other dependencies
+// come from the parent artifact ids of the modules.
+func CycloneDX(info model.BuildInfo, now time.Time) BOM {
+	bom := BOM{
+		BomFormat:    "CycloneDX",
+		SpecVersion:  CycloneDXVersion,
+		SerialNumber: "urn:uuid:" + newUUID(),
+		Version:      1,
+		Components:   []Component{},
+		Dependencies: []Dependency{},
+	}
+	bom.Metadata.Timestamp = now.UTC().Format(time.RFC3339)
+	bom.Metadata.Tools.Components = []Component{{Type: "application", Name: toolName}}
+
+	root := Component{Type: "application", BomRef: "project", Name: info.Path}
+	if root.Name == "" {
+		root.Name = "project"
+	}
+	bom.Metadata.Component = &root
+
+	refs := bomRefs(info.Modules)
+	byID := artifactRefs(info.Modules, refs)
+
+	dependsOn := map[string][]string{}
+	var direct []string
+	for i, m := range info.Modules {
+		bom.Components = append(bom.Components, component(m, refs[i]))
+
+		if m.Direct {
+			direct = append(direct, refs[i])
+		}
+		for _, parent := range m.ParentIDs {
+			for _, ref := range byID[parent] {
+				dependsOn[ref] = append(dependsOn[ref], refs[i])
+			}
+		}
+	}
+
+	bom.Dependencies = append(bom.Dependencies, Dependency{Ref: root.BomRef, DependsOn: unique(direct)})
+	for _, ref := range refs {
+		bom.Dependencies = append(bom.Dependencies, Dependency{Ref: ref, DependsOn: unique(dependsOn[ref])})
+	}
+
+	return bom
+}
+
+// WriteCycloneDX writes the BOM of the modules as indented json
+func WriteCycloneDX(w io.Writer, info model.BuildInfo, now time.Time) error {
+	data, err := json.MarshalIndent(CycloneDX(info, now), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+func component(m model.Module, ref string) Component {
+	c := Component{
+		Type:        "library",
+		BomRef:      ref,
+		Name:        moduleName(m),
+		Version:     m.Version,
+		Description: m.Info.Description,
+		Scope:       scope(m.Scope),
+		Purl:        m.Purl,
+	}
+	if p, err := purl.Parse(m.Purl); err == nil {
+		c.Group, c.Name = p.Namespace, p.Name
+	}
+
+	for _, algorithm := range sortedKeys(m.Hashes) {
+		c.Hashes = append(c.Hashes, Hash{Alg: algorithm, Content: m.Hashes[algorithm]})
+	}
+
+	if m.Info.FullName != "" {
+		c.Supplier = &Entity{Name: m.Info.FullName}
+		if m.Repository != "" {
+			c.Supplier.URL = []string{"https://" + m.Repository}
+		}
+	}
+
+	if license := m.Info.SPDX; license != "" && license != "NOASSERTION" && license != "NONE" {
+		if spdxID.MatchString(license) {
+			l := License{License: &struct {
+				ID string `json:"id"`
+			}{ID: license}}
+			c.Licenses = []License{l}
+		} else {
+			c.Licenses = []License{{Expression: license}}
+		}
+	}
+
+	if m.Repository != "" {
+		c.ExternalReferences = append(c.ExternalReferences, ExternalReference{Type: "vcs", URL: "https://" + m.Repository})
+	}
+	if strings.HasPrefix(m.Path, "https://") {
+		c.ExternalReferences = append(c.ExternalReferences, ExternalReference{Type: "website", URL: m.Path})
+	}
+
+	if !m.Info.Release.IsZero() {
+		c.Properties = append(c.Properties, Property{Name: toolName + ":release", Value: m.Info.Release.Format("2006-01-02")})
+	}
+	if m.Ecosystem != "" {
+		c.Properties = append(c.Properties, Property{Name: toolName + ":ecosystem", Value: m.Ecosystem})
+	}
+
+	return c
+}
+
+// scope maps the dependency scope of the SBOM to the CycloneDX scope,
+// dependencies not needed at runtime are excluded
+func scope(s string) string {
+	switch s {
+	case "":
+		return ""
+	case "optional", "excluded":
+		return s
+	case "dev", "test", "build":
+		return "excluded"
+	}
+
+	return "required"
+}
+
+// moduleName is the name the parents of other modules refer to the module by
+func moduleName(m model.Module) string {
+	if p, err := purl.Parse(m.Purl); err == nil {
+		return p.FullName()
+	}
+	if m.Name != "" {
+		return m.Name
+	}
+
+	return m.Path
+}
+
+// bomRefs returns a unique reference per module: the purl, else the artifact id
+func bomRefs(modules []model.Module) []string {
+	refs := make([]string, len(modules))
+	used := map[string]bool{"project": true}
+	for i, m := range modules {
+		ref := m.Purl
+		if ref == "" || used[ref] {
+			ref = m.Hash
+		}
+		if ref == "" || used[ref] {
+			ref = fmt.Sprintf("component-%d", i+1)
+		}
+		used[ref] = true
+		refs[i] = ref
+	}
+
+	return refs
+}
+
+// artifactRefs maps the artifact ids of the modules to their references, the
+// names of the parents would link every version of a package
+func artifactRefs(modules []model.Module, refs []string) map[string][]string {
+	byID := map[string][]string{}
+	for i, m := range modules {
+		if m.Hash != "" {
+			byID[m.Hash] = append(byID[m.Hash], refs[i])
+		}
+	}
+
+	return byID
+}
+
+// unique returns the sorted distinct refs, never nil so json has an empty list
+func unique(refs []string) []string {
+	result := []string{}
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		if !seen[ref] {
+			seen[ref] = true
+			result = append(result, ref)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// sortedKeys returns the keys of m in order, so the output does not change between runs
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// newUUID returns a random version 4 uuid
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
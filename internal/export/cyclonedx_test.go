@@ -0,0 +1,38 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+
+	"syfttoymlconverter/internal/model"
+)
+
+func TestCycloneDXDependencies(t *testing.T) {
+	info := model.BuildInfo{
+		Path: "github.com/example/project",
+		Modules: []model.Module{
+			{Name: "tslib", Version: "1.14.1", Hash: "a1", Purl: "pkg:npm/tslib@1.14.1", Direct: true},
+			{Name: "tslib", Version: "2.4.0", Hash: "a2", Purl: "pkg:npm/tslib@2.4.0", Direct: true},
+			{
+				Name:      "left-pad",
+				Version:   "1.3.0",
+				Hash:      "b",
+				Purl:      "pkg:npm/left-pad@1.3.0",
+				Parents:   []string{"tslib"},
+				ParentIDs: []string{"a2"},
+			},
+		},
+	}
+
+	bom := CycloneDX(info, testNow)
+
+	want := []Dependency{
+		{Ref: "project", DependsOn: []string{"pkg:npm/tslib@1.14.1", "pkg:npm/tslib@2.4.0"}},
+		{Ref: "pkg:npm/tslib@1.14.1", DependsOn: []string{}},
+		{Ref: "pkg:npm/tslib@2.4.0", DependsOn: []string{"pkg:npm/left-pad@1.3.0"}},
+		{Ref: "pkg:npm/left-pad@1.3.0", DependsOn: []string{}},
+	}
+	if !reflect.DeepEqual(bom.Dependencies, want) {
+		t.Errorf("dependencies =\n%v\nwant\n%v", bom.Dependencies, want)
+	}
+}
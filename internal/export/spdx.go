@@ -0,0 +1,401 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"syfttoymlconverter/internal/model"
+	"time"
+)
+
+// SPDXVersion is the spec version of the written documents
+const SPDXVersion = "SPDX-2.3"
+
+// noAssertion marks SPDX fields the converter knows nothing about
+const noAssertion = "NOASSERTION"
+
+// spdx relationship types written by the exporter
+const (
+	spdxDescribes       = "DESCRIBES"
+	spdxDependsOn       = "DEPENDS_ON"
+	spdxDevDependencyOf = "DEV_DEPENDENCY_OF"
+)
+
+// SPDXDocument is an SPDX json document, the tag-value writer prints the same fields
+type SPDXDocument struct {
+	SPDXVersion       string `json:"spdxVersion"`
+	DataLicense       string `json:"dataLicense"`
+	SPDXID            string `json:"SPDXID"`
+	Name              string `json:"name"`
+	DocumentNamespace string `json:"documentNamespace"`
+	CreationInfo      struct {
+		Created  string   `json:"created"`
+		Creators []string `json:"creators"`
+	} `json:"creationInfo"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []SPDXPackage      `json:"packages"`
+	Relationships     []SPDXRelationship `json:"relationships"`
+}
+
+// SPDXPackage is a package of the document, the project or a module
+type SPDXPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	Supplier              string            `json:"supplier,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	LicenseConcluded      string            `json:"licenseConcluded"`
+	LicenseDeclared       string            `json:"licenseDeclared"`
+	CopyrightText         string            `json:"copyrightText"`
+	Description           string            `json:"description,omitempty"`
+	ReleaseDate           string            `json:"releaseDate,omitempty"`
+	Checksums             []SPDXChecksum    `json:"checksums,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+	ExternalRefs          []SPDXExternalRef `json:"externalRefs,omitempty"`
+}
+
+// SPDXChecksum is a checksum of a package
+type SPDXChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+// SPDXExternalRef is the purl of a package
+type SPDXExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// SPDXRelationship relates two elements of the document
+type SPDXRelationship struct {
+	SpdxElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSpdxElement string `json:"relatedSpdxElement"`
+}
+
+//nolint:gochecknoglobals // compiled once
+var (
+	// spdxIDChars are the characters allowed in an SPDXID after SPDXRef-
+	spdxIDChars = regexp.MustCompile(`[^A-Za-z0-9.-]+`)
+	// spdxRef is a valid SPDXID
+	spdxRef = regexp.MustCompile(`^SPDXRef-[A-Za-z0-9.-]+$`)
+	// spdxLicenseRef is a license id or reference of an expression, optionally with a +
+	spdxLicenseRef = regexp.MustCompile(`^(DocumentRef-[A-Za-z0-9.-]+:)?[A-Za-z0-9.-]+\+?$`)
+)
+
+// SPDX returns the document of the modules. It describes the project named by
+// info.Path, the project depends on the direct modules and every module on the
+// modules whose artifact id it names as parent. Dev, test, build and excluded dependencies are related by
+// DEV_DEPENDENCY_OF instead of DEPENDS_ON.
+func SPDX(info model.BuildInfo, now time.Time) SPDXDocument {
+	var doc SPDXDocument
+	doc.SPDXVersion = SPDXVersion
+	doc.DataLicense = "CC0-1.0"
+	doc.SPDXID = "SPDXRef-DOCUMENT"
+	doc.Name = info.Path
+	if doc.Name == "" {
+		doc.Name = "project"
+	}
+	doc.DocumentNamespace = fmt.Sprintf("https://spdx.org/spdxdocs/%s-%s",
+		strings.Trim(spdxIDChars.ReplaceAllString(doc.Name, "-"), "-"), newUUID())
+	doc.CreationInfo.Created = now.UTC().Format(time.RFC3339)
+	doc.CreationInfo.Creators = []string{"Tool: " + toolName}
+
+	root := SPDXPackage{
+		Name:                  doc.Name,
+		SPDXID:                "SPDXRef-Project",
+		DownloadLocation:      noAssertion,
+		LicenseConcluded:      noAssertion,
+		LicenseDeclared:       noAssertion,
+		CopyrightText:         noAssertion,
+		PrimaryPackagePurpose: "APPLICATION",
+	}
+	doc.DocumentDescribes = []string{root.SPDXID}
+	doc.Packages = []SPDXPackage{root}
+	doc.Relationships = []SPDXRelationship{{
+		SpdxElementID:      doc.SPDXID,
+		RelationshipType:   spdxDescribes,
+		RelatedSpdxElement: root.SPDXID,
+	}}
+
+	ids := spdxIDs(info.Modules)
+	byID := artifactRefs(info.Modules, ids)
+
+	var relationships []SPDXRelationship
+	for i, m := range info.Modules {
+		doc.Packages = append(doc.Packages, spdxPackage(m, ids[i]))
+
+		var parents []string
+		if m.Direct {
+			parents = append(parents, root.SPDXID)
+		}
+		for _, parent := range m.ParentIDs {
+			parents = append(parents, byID[parent]...)
+		}
+		for _, parent := range parents {
+			relationships = append(relationships, dependency(parent, ids[i], m.Scope))
+		}
+	}
+
+	sort.SliceStable(relationships, func(i, j int) bool {
+		if relationships[i].SpdxElementID != relationships[j].SpdxElementID {
+			return relationships[i].SpdxElementID < relationships[j].SpdxElementID
+		}
+		return relationships[i].RelatedSpdxElement < relationships[j].RelatedSpdxElement
+	})
+	for i, r := range relationships {
+		if i == 0 || r != relationships[i-1] {
+			doc.Relationships = append(doc.Relationships, r)
+		}
+	}
+
+	return doc
+}
+
+// dependency relates the module id to the package parent depending on it
+func dependency(parent, id, scope string) SPDXRelationship {
+	switch scope {
+	case "dev", "test", "build", "excluded":
+		return SPDXRelationship{SpdxElementID: id, RelationshipType: spdxDevDependencyOf, RelatedSpdxElement: parent}
+	}
+
+	return SPDXRelationship{SpdxElementID: parent, RelationshipType: spdxDependsOn, RelatedSpdxElement: id}
+}
+
+func spdxPackage(m model.Module, id string) SPDXPackage {
+	p := SPDXPackage{
+		Name:                  moduleName(m),
+		SPDXID:                id,
+		VersionInfo:           m.Version,
+		Supplier:              noAssertion,
+		DownloadLocation:      noAssertion,
+		LicenseConcluded:      noAssertion,
+		LicenseDeclared:       noAssertion,
+		CopyrightText:         noAssertion,
+		Description:           m.Info.Description,
+		PrimaryPackagePurpose: "LIBRARY",
+	}
+
+	if m.Info.FullName != "" {
+		p.Supplier = "Organization: " + m.Info.FullName
+	}
+	if m.Repository != "" {
+		p.DownloadLocation = "git+https://" + m.Repository
+	}
+	if license := m.Info.SPDX; license != "NONE" && isSPDXExpression(license) {
+		p.LicenseDeclared = license
+	}
+	if !m.Info.Release.IsZero() {
+		p.ReleaseDate = m.Info.Release.UTC().Format(time.RFC3339)
+	}
+	for _, algorithm := range sortedKeys(m.Hashes) {
+		// SPDX writes SHA512 where CycloneDX writes SHA-512, but keeps SHA3-512 and BLAKE2b-512
+		name := algorithm
+		if strings.HasPrefix(name, "SHA-") {
+			name = strings.Replace(name, "-", "", 1)
+		}
+		p.Checksums = append(p.Checksums, SPDXChecksum{Algorithm: name, ChecksumValue: m.Hashes[algorithm]})
+	}
+	if m.Purl != "" {
+		p.ExternalRefs = []SPDXExternalRef{{
+			ReferenceCategory: "PACKAGE-MANAGER",
+			ReferenceType:     "purl",
+			ReferenceLocator:  m.Purl,
+		}}
+	}
+
+	return p
+}
+
+// isSPDXExpression reports whether license is a license id or an expression
+// combining them with AND, OR, WITH and parentheses
+func isSPDXExpression(license string) bool {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(license))
+	depth := 0
+	operand := true // an id or ( is expected next
+	for _, token := range tokens {
+		switch {
+		case operand && token == "(":
+			depth++
+		case operand && spdxLicenseRef.MatchString(token) && !isOperator(token):
+			operand = false
+		case !operand && token == ")" && depth > 0:
+			depth--
+		case !operand && isOperator(token):
+			operand = true
+		default:
+			return false
+		}
+	}
+
+	return len(tokens) > 0 && !operand && depth == 0
+}
+
+func isOperator(token string) bool {
+	switch strings.ToUpper(token) {
+	case "AND", "OR", "WITH":
+		return true
+	}
+
+	return false
+}
+
+// spdxIDs returns a unique SPDXID per module built from name and version
+func spdxIDs(modules []model.Module) []string {
+	ids := make([]string, len(modules))
+	used := map[string]bool{}
+	for i, m := range modules {
+		base := "SPDXRef-Package-" + strings.Trim(spdxIDChars.ReplaceAllString(moduleName(m)+"-"+m.Version, "-"), "-")
+		id := base
+		for n := 2; used[id]; n++ {
+			id = fmt.Sprintf("%s-%d", base, n)
+		}
+		used[id] = true
+		ids[i] = id
+	}
+
+	return ids
+}
+
+// Check verifies the fields the SPDX 2.3 schema requires, the format of the
+// SPDXIDs and that relationships only refer to elements of the document
+func (doc SPDXDocument) Check() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if doc.SPDXVersion != SPDXVersion {
+		add("spdxVersion is %q, expected %s", doc.SPDXVersion, SPDXVersion)
+	}
+	if doc.DataLicense != "CC0-1.0" {
+		add("dataLicense is %q, expected CC0-1.0", doc.DataLicense)
+	}
+	if doc.SPDXID != "SPDXRef-DOCUMENT" {
+		add("SPDXID of the document is %q, expected SPDXRef-DOCUMENT", doc.SPDXID)
+	}
+	if doc.Name == "" {
+		add("name is missing")
+	}
+	if !strings.Contains(doc.DocumentNamespace, "://") || strings.Contains(doc.DocumentNamespace, "#") {
+		add("documentNamespace %q is not a uri without fragment", doc.DocumentNamespace)
+	}
+	if _, err := time.Parse(time.RFC3339, doc.CreationInfo.Created); err != nil {
+		add("creationInfo.created: %v", err)
+	}
+	if len(doc.CreationInfo.Creators) == 0 {
+		add("creationInfo.creators is empty")
+	}
+
+	ids := map[string]bool{doc.SPDXID: true}
+	for i, p := range doc.Packages {
+		if !spdxRef.MatchString(p.SPDXID) {
+			add("packages[%d].SPDXID %q is invalid", i, p.SPDXID)
+		}
+		if ids[p.SPDXID] {
+			add("packages[%d].SPDXID %q is not unique", i, p.SPDXID)
+		}
+		ids[p.SPDXID] = true
+		if p.Name == "" {
+			add("packages[%d].name is missing", i)
+		}
+		if p.DownloadLocation == "" {
+			add("packages[%d].downloadLocation is missing", i)
+		}
+	}
+	for _, id := range doc.DocumentDescribes {
+		if !ids[id] {
+			add("documentDescribes names the unknown element %s", id)
+		}
+	}
+	for i, r := range doc.Relationships {
+		if !ids[r.SpdxElementID] || !ids[r.RelatedSpdxElement] {
+			add("relationships[%d] %s %s %s refers to an unknown element",
+				i, r.SpdxElementID, r.RelationshipType, r.RelatedSpdxElement)
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid SPDX document:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	return nil
+}
+
+// WriteSPDXJSON checks the document of the modules and writes it as indented json
+func WriteSPDXJSON(w io.Writer, info model.BuildInfo, now time.Time) error {
+	doc := SPDX(info, now)
+	if err := doc.Check(); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// WriteSPDXTagValue checks the document of the modules and writes it in the tag-value format
+func WriteSPDXTagValue(w io.Writer, info model.BuildInfo, now time.Time) error {
+	doc := SPDX(info, now)
+	if err := doc.Check(); err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	tag := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "%s: %s\n", name, value)
+		}
+	}
+
+	tag("SPDXVersion", doc.SPDXVersion)
+	tag("DataLicense", doc.DataLicense)
+	tag("SPDXID", doc.SPDXID)
+	tag("DocumentName", doc.Name)
+	tag("DocumentNamespace", doc.DocumentNamespace)
+	for _, creator := range doc.CreationInfo.Creators {
+		tag("Creator", creator)
+	}
+	tag("Created", doc.CreationInfo.Created)
+
+	for _, p := range doc.Packages {
+		fmt.Fprintf(&b, "\n##### Package: %s\n\n", p.Name)
+		tag("PackageName", p.Name)
+		tag("SPDXID", p.SPDXID)
+		tag("PackageVersion", p.VersionInfo)
+		tag("PackageSupplier", p.Supplier)
+		tag("PackageDownloadLocation", p.DownloadLocation)
+		tag("FilesAnalyzed", fmt.Sprint(p.FilesAnalyzed))
+		tag("PackageLicenseConcluded", p.LicenseConcluded)
+		tag("PackageLicenseDeclared", p.LicenseDeclared)
+		tag("PackageCopyrightText", p.CopyrightText)
+		if p.Description != "" {
+			tag("PackageDescription", "<text>"+p.Description+"</text>")
+		}
+		tag("ReleaseDate", p.ReleaseDate)
+		for _, c := range p.Checksums {
+			tag("PackageChecksum", c.Algorithm+": "+c.ChecksumValue)
+		}
+		tag("PrimaryPackagePurpose", p.PrimaryPackagePurpose)
+		for _, ref := range p.ExternalRefs {
+			tag("ExternalRef", ref.ReferenceCategory+" "+ref.ReferenceType+" "+ref.ReferenceLocator)
+		}
+	}
+
+	b.WriteString("\n")
+	for _, r := range doc.Relationships {
+		tag("Relationship", r.SpdxElementID+" "+r.RelationshipType+" "+r.RelatedSpdxElement)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,262 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"syfttoymlconverter/internal"
+	"syfttoymlconverter/internal/model"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// testNow is the creation time of the test documents
+var testNow = time.Date(2024, 2, 3, 4, 5, 6, 0, time.UTC) //nolint:gochecknoglobals // test fixture
+
+// testBuildInfo is a project with two direct modules of the same name and version,
+// a transitive dependency of the first of them and a dev dependency of both
+func testBuildInfo() model.BuildInfo {
+	return model.BuildInfo{
+		Path: "github.com/example/project",
+		Modules: []model.Module{
+			{
+				Name:       "@scope/pkg",
+				Version:    "1.0.0+build",
+				Hash:       "p1",
+				Purl:       "pkg:npm/%40scope/pkg@1.0.0%2Bbuild",
+				Repository: "github.com/scope/pkg",
+				Direct:     true,
+				Hashes: map[string]string{
+					"SHA-512":     "ab12",
+					"SHA3-256":    "cd34",
+					"BLAKE2b-256": "ef56",
+				},
+				Info: model.RepoInfo{
+					FullName:    "Scope Inc",
+					SPDX:        "(MIT OR Apache-2.0)",
+					Description: "a scoped package",
+					Release:     time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC),
+				},
+			},
+			{
+				Name:    "@scope/pkg",
+				Version: "1.0.0+build",
+				Hash:    "p2",
+				Direct:  true,
+				Info:    model.RepoInfo{SPDX: "see LICENSE file"},
+			},
+			{
+				Name:      "left-pad",
+				Version:   "1.3.0",
+				Hash:      "lp",
+				Purl:      "pkg:npm/left-pad@1.3.0",
+				Parents:   []string{"@scope/pkg"},
+				ParentIDs: []string{"p1"},
+				Info:      model.RepoInfo{SPDX: "WTFPL"},
+			},
+			{
+				Name:      "jest",
+				Version:   "29.0.0",
+				Hash:      "j",
+				Purl:      "pkg:npm/jest@29.0.0",
+				Scope:     "dev",
+				Parents:   []string{"@scope/pkg"},
+				ParentIDs: []string{"p1", "p2"},
+				Info:      model.RepoInfo{SPDX: "NONE"},
+			},
+		},
+	}
+}
+
+// TestSPDXJSONSchema validates the json document against the official SPDX 2.3
+// schema, copied from the spdx-spec repository into testdata
+func TestSPDXJSONSchema(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteSPDXJSON(&out, testBuildInfo(), testNow); err != nil {
+		t.Fatal(err)
+	}
+
+	schema, err := jsonschema.Compile("testdata/spdx-schema-2.3.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := schema.Validate(doc); err != nil {
+		t.Errorf("%#v", err)
+	}
+}
+
+func TestSPDXIDs(t *testing.T) {
+	doc := SPDX(testBuildInfo(), testNow)
+
+	want := []string{
+		"SPDXRef-Project",
+		"SPDXRef-Package-scope-pkg-1.0.0-build",
+		"SPDXRef-Package-scope-pkg-1.0.0-build-2",
+		"SPDXRef-Package-left-pad-1.3.0",
+		"SPDXRef-Package-jest-29.0.0",
+	}
+	var got []string
+	for _, p := range doc.Packages {
+		got = append(got, p.SPDXID)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SPDXIDs = %v, want %v", got, want)
+	}
+
+	format := regexp.MustCompile(`^SPDXRef-[A-Za-z0-9.-]+$`)
+	for _, id := range got {
+		if !format.MatchString(id) {
+			t.Errorf("SPDXID %q does not match %s", id, format)
+		}
+	}
+	if err := doc.Check(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSPDXPackages(t *testing.T) {
+	doc := SPDX(testBuildInfo(), testNow)
+
+	tests := []struct {
+		id, supplier, licenseDeclared, downloadLocation string
+	}{
+		{"SPDXRef-Package-scope-pkg-1.0.0-build", "Organization: Scope Inc", "(MIT OR Apache-2.0)", "git+https://github.com/scope/pkg"},
+		{"SPDXRef-Package-scope-pkg-1.0.0-build-2", noAssertion, noAssertion, noAssertion},
+		{"SPDXRef-Package-left-pad-1.3.0", noAssertion, "WTFPL", noAssertion},
+		{"SPDXRef-Package-jest-29.0.0", noAssertion, noAssertion, noAssertion},
+	}
+	for i, tt := range tests {
+		p := doc.Packages[i+1]
+		if p.SPDXID != tt.id {
+			t.Fatalf("package %d is %s, want %s", i+1, p.SPDXID, tt.id)
+		}
+		if p.Supplier != tt.supplier {
+			t.Errorf("%s: supplier = %q, want %q", p.SPDXID, p.Supplier, tt.supplier)
+		}
+		if p.LicenseDeclared != tt.licenseDeclared {
+			t.Errorf("%s: licenseDeclared = %q, want %q", p.SPDXID, p.LicenseDeclared, tt.licenseDeclared)
+		}
+		if p.DownloadLocation != tt.downloadLocation {
+			t.Errorf("%s: downloadLocation = %q, want %q", p.SPDXID, p.DownloadLocation, tt.downloadLocation)
+		}
+	}
+
+	wantChecksums := []SPDXChecksum{
+		{Algorithm: "BLAKE2b-256", ChecksumValue: "ef56"},
+		{Algorithm: "SHA512", ChecksumValue: "ab12"},
+		{Algorithm: "SHA3-256", ChecksumValue: "cd34"},
+	}
+	if got := doc.Packages[1].Checksums; !reflect.DeepEqual(got, wantChecksums) {
+		t.Errorf("checksums = %v, want %v", got, wantChecksums)
+	}
+}
+
+func TestIsSPDXExpression(t *testing.T) {
+	tests := map[string]bool{
+		"MIT":               true,
+		"GPL-2.0+":          true,
+		"LicenseRef-custom": true,
+		"DocumentRef-spdx-tool-1.2:LicenseRef-MIT-Style-2": true,
+		"MIT OR Apache-2.0":                         true,
+		"(MIT OR Apache-2.0) AND BSD-3-Clause":      true,
+		"GPL-2.0-only WITH Classpath-exception-2.0": true,
+		"":                       false,
+		"see LICENSE file":       false,
+		"MIT,Apache-2.0":         false,
+		"MIT OR":                 false,
+		"(MIT":                   false,
+		"MIT)":                   false,
+		"AND":                    false,
+		"http://example.com/lic": false,
+	}
+	for license, want := range tests {
+		if got := isSPDXExpression(license); got != want {
+			t.Errorf("isSPDXExpression(%q) = %v, want %v", license, got, want)
+		}
+	}
+}
+
+func TestSPDXRelationships(t *testing.T) {
+	doc := SPDX(testBuildInfo(), testNow)
+
+	want := []SPDXRelationship{
+		{"SPDXRef-DOCUMENT", "DESCRIBES", "SPDXRef-Project"},
+		{"SPDXRef-Package-jest-29.0.0", "DEV_DEPENDENCY_OF", "SPDXRef-Package-scope-pkg-1.0.0-build"},
+		{"SPDXRef-Package-jest-29.0.0", "DEV_DEPENDENCY_OF", "SPDXRef-Package-scope-pkg-1.0.0-build-2"},
+		{"SPDXRef-Package-scope-pkg-1.0.0-build", "DEPENDS_ON", "SPDXRef-Package-left-pad-1.3.0"},
+		{"SPDXRef-Project", "DEPENDS_ON", "SPDXRef-Package-scope-pkg-1.0.0-build"},
+		{"SPDXRef-Project", "DEPENDS_ON", "SPDXRef-Package-scope-pkg-1.0.0-build-2"},
+	}
+	if !reflect.DeepEqual(doc.Relationships, want) {
+		t.Errorf("relationships =\n%v\nwant\n%v", doc.Relationships, want)
+	}
+}
+
+func TestSPDXTagValueRoundTrip(t *testing.T) {
+	var out bytes.Buffer
+	if err := WriteSPDXTagValue(&out, testBuildInfo(), testNow); err != nil {
+		t.Fatal(err)
+	}
+
+	syft, err := internal.ReadSPDXTagValue(out.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if syft.Source.Target != "github.com/example/project" {
+		t.Errorf("source target = %q", syft.Source.Target)
+	}
+
+	var artifacts []string
+	for _, a := range syft.Artifacts {
+		artifacts = append(artifacts, fmt.Sprintf("%s %s %s %v %s", a.Name, a.Version, a.Purl, a.Licenses, a.Scope))
+	}
+	wantArtifacts := []string{
+		"@scope/pkg 1.0.0+build pkg:npm/%40scope/pkg@1.0.0%2Bbuild [(MIT OR Apache-2.0)] ",
+		"@scope/pkg 1.0.0+build  [] ",
+		"left-pad 1.3.0 pkg:npm/left-pad@1.3.0 [WTFPL] ",
+		"jest 29.0.0 pkg:npm/jest@29.0.0 [] dev",
+	}
+	if !reflect.DeepEqual(artifacts, wantArtifacts) {
+		t.Errorf("artifacts =\n%s\nwant\n%s", strings.Join(artifacts, "\n"), strings.Join(wantArtifacts, "\n"))
+	}
+
+	wantDigests := []internal.Digest{
+		{Algorithm: "BLAKE2b-256", Value: "ef56"},
+		{Algorithm: "SHA512", Value: "ab12"},
+		{Algorithm: "SHA3-256", Value: "cd34"},
+	}
+	if got := syft.Artifacts[0].Digests; !reflect.DeepEqual(got, wantDigests) {
+		t.Errorf("digests = %v, want %v", got, wantDigests)
+	}
+
+	var dependencies []string
+	for _, r := range syft.ArtifactRelationships {
+		if r.Type == "dependency-of" {
+			dependencies = append(dependencies, r.Parent+" -> "+r.Child)
+		}
+	}
+	sort.Strings(dependencies)
+	source := syft.Source.ID
+	wantDependencies := []string{
+		"SPDXRef-Package-jest-29.0.0 -> SPDXRef-Package-scope-pkg-1.0.0-build",
+		"SPDXRef-Package-jest-29.0.0 -> SPDXRef-Package-scope-pkg-1.0.0-build-2",
+		"SPDXRef-Package-left-pad-1.3.0 -> SPDXRef-Package-scope-pkg-1.0.0-build",
+		"SPDXRef-Package-scope-pkg-1.0.0-build -> " + source,
+		"SPDXRef-Package-scope-pkg-1.0.0-build-2 -> " + source,
+	}
+	if !reflect.DeepEqual(dependencies, wantDependencies) {
+		t.Errorf("dependencies =\n%s\nwant\n%s", strings.Join(dependencies, "\n"), strings.Join(wantDependencies, "\n"))
+	}
+}
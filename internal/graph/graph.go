@@ -0,0 +1,225 @@
+// Package graph holds the dependency graph of the artifacts of an SBOM
+package graph
+
+import (
+	"sort"
+	"syfttoymlconverter/internal"
+	"syfttoymlconverter/internal/purl"
+)
+
+// syft relationship whose parent is a dependency of its child
+const dependencyOf = "dependency-of"
+
+// Node is an artifact of the graph
+type Node struct {
+	ID      string
+	Name    string
+	Version string
+}
+
+// Graph is a directed dependency graph, an edge from a to b means a depends on b.
+// Artifacts nothing depends on are the direct dependencies of the scanned project.
+type Graph struct {
+	nodes        map[string]Node
+	order        []string
+	byName       map[string][]string
+	dependencies map[string][]string
+	dependents   map[string][]string
+	// depths is computed on first use and reset by AddEdge
+	depths map[string]int
+}
+
+func New() *Graph {
+	return &Graph{
+		nodes:        map[string]Node{},
+		byName:       map[string][]string{},
+		dependencies: map[string][]string{},
+		dependents:   map[string][]string{},
+	}
+}
+
+// FromSyft builds the graph of the artifacts and their dependency-of relationships.
+// Syft only records those for some catalogers, the contains relationships of the
+// source do not carry dependency information.
+func FromSyft(syft *internal.Syft) *Graph {
+	g := New()
+	for _, a := range syft.Artifacts {
+		name := a.Name
+		if p, err := purl.Parse(a.Purl); err == nil {
+			name = p.FullName()
+		}
+		g.AddNode(a.ID, name, a.Version)
+	}
+
+	for _, r := range syft.ArtifactRelationships {
+		if r.Type == dependencyOf {
+			g.AddEdge(r.Child, r.Parent)
+		}
+	}
+
+	return g
+}
+
+// AddNode adds an artifact, an existing node with the same id is kept
+func (g *Graph) AddNode(id, name, version string) {
+	if _, ok := g.nodes[id]; ok {
+		return
+	}
+
+	g.nodes[id] = Node{ID: id, Name: name, Version: version}
+	g.order = append(g.order, id)
+	g.byName[name] = append(g.byName[name], id)
+}
+
+// AddEdge records that from depends on to. Unknown nodes, self references and
+// duplicates are ignored, the result reports whether the edge was added.
+func (g *Graph) AddEdge(from, to string) bool {
+	if from == to {
+		return false
+	}
+	if _, ok := g.nodes[from]; !ok {
+		return false
+	}
+	if _, ok := g.nodes[to]; !ok {
+		return false
+	}
+	for _, id := range g.dependencies[from] {
+		if id == to {
+			return false
+		}
+	}
+
+	g.dependencies[from] = append(g.dependencies[from], to)
+	g.dependents[to] = append(g.dependents[to], from)
+	g.depths = nil
+
+	return true
+}
+
+// HasEdges reports whether any dependency is known
+func (g *Graph) HasEdges() bool {
+	return len(g.dependencies) > 0
+}
+
+// Node returns the node with id
+func (g *Graph) Node(id string) (Node, bool) {
+	n, ok := g.nodes[id]
+	return n, ok
+}
+
+// Nodes returns all nodes in the order they were added
+func (g *Graph) Nodes() []Node {
+	nodes := make([]Node, 0, len(g.order))
+	for _, id := range g.order {
+		nodes = append(nodes, g.nodes[id])
+	}
+
+	return nodes
+}
+
+// Lookup returns the ids of all versions of the package with exactly name
+func (g *Graph) Lookup(name string) []string {
+	return g.byName[name]
+}
+
+// Dependencies returns the ids of the direct dependencies of id
+func (g *Graph) Dependencies(id string) []string {
+	return g.dependencies[id]
+}
+
+// Dependents returns the ids of the artifacts that directly depend on id
+func (g *Graph) Dependents(id string) []string {
+	return g.dependents[id]
+}
+
+// Parents returns the sorted, distinct names of the artifacts that directly depend on id
+func (g *Graph) Parents(id string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, parent := range g.dependents[id] {
+		name := g.nodes[parent].Name
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Transitive returns the ids of all artifacts id depends on directly or indirectly
+func (g *Graph) Transitive(id string) []string {
+	var result []string
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, dep := range g.dependencies[current] {
+			if !seen[dep] {
+				seen[dep] = true
+				result = append(result, dep)
+				queue = append(queue, dep)
+			}
+		}
+	}
+
+	return result
+}
+
+// IsDirect reports whether id is a direct dependency of the project, nothing else depends on it
+func (g *Graph) IsDirect(id string) bool {
+	_, ok := g.nodes[id]
+	return ok && len(g.dependents[id]) == 0
+}
+
+// Depth returns the shortest distance of id to the project, 1 for direct dependencies.
+// Artifacts only reachable through a cycle count from the first of them. It is 0 for unknown ids.
+func (g *Graph) Depth(id string) int {
+	if g.depths == nil {
+		g.depths = g.computeDepths()
+	}
+
+	return g.depths[id]
+}
+
+// computeDepths runs a breadth first search from the direct dependencies
+func (g *Graph) computeDepths() map[string]int {
+	depths := map[string]int{}
+
+	var queue []string
+	visit := func(start []string) {
+		for _, id := range start {
+			if _, ok := depths[id]; !ok {
+				depths[id] = 1
+				queue = append(queue, id)
+			}
+		}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for _, dep := range g.dependencies[current] {
+				if _, ok := depths[dep]; !ok {
+					depths[dep] = depths[current] + 1
+					queue = append(queue, dep)
+				}
+			}
+		}
+	}
+
+	var roots []string
+	for _, id := range g.order {
+		if g.IsDirect(id) {
+			roots = append(roots, id)
+		}
+	}
+	visit(roots)
+
+	// cycles without a direct dependency leading into them
+	for _, id := range g.order {
+		visit([]string{id})
+	}
+
+	return depths
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"log"
+	"os"
 	"syfttoymlconverter/internal"
 	"syfttoymlconverter/internal/api_interfaces"
 	"syfttoymlconverter/internal/model"
@@ -16,6 +17,6 @@ func (Conan) FetchMetadata(syft *internal.Syft) (model.BuildInfo, error) {
 		log.Printf("Error getting metadata: %v", err)
 	}
 	conan := api_interfaces.ParseConanOutput(string(conanInfo))
-	fmt.Println(conan)
+	fmt.Fprintln(os.Stderr, conan)
 	return model.BuildInfo{}, nil
 }
@@ -12,7 +12,7 @@ func (Dotnet) FetchMetadata(syft *internal.Syft) (model.BuildInfo, error) {
 	var nuget api_interfaces.Nuget
 	models, _ := nuget.ParseEmbeddedModules(syft)
 	nuget.SetRepoInfo(syft, &models)
-	nuget.SetParents(&models)
+	nuget.SetParents(syft, &models)
 	return models, nil
 }
 
@@ -3,6 +3,7 @@ package handler
 import (
 	"syfttoymlconverter/internal"
 	"syfttoymlconverter/internal/api_interfaces"
+	"syfttoymlconverter/internal/graph"
 	"syfttoymlconverter/internal/model"
 )
 
@@ -13,6 +14,7 @@ func (Go) FetchMetadata(syft *internal.Syft) (model.BuildInfo, error) {
 	models, _ := api_interfaces.ParseEmbeddedModules(syft)
 
 	api_interfaces.SetRepoInfoPooled(&models, 5)
+	api_interfaces.SetGraph(&models, graph.FromSyft(syft))
 
 	return models, nil
 }
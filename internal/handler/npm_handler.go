@@ -12,7 +12,7 @@ func (Npm) FetchMetadata(syft *internal.Syft) (model.BuildInfo, error) {
 	var npm api_interfaces.NPM
 	models, _ := npm.ParseEmbeddedModules(syft)
 	npm.SetRepoInfo(syft, &models)
-	npm.SetParents(&models)
+	npm.SetParents(syft, &models)
 	return models, nil
 }
 
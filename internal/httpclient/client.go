@@ -0,0 +1,124 @@
+// Package httpclient is the http client shared by the registry and forge lookups.
+// It adds timeouts, retries with exponential backoff, Retry-After support,
+// proxy configuration and typed errors on top of net/http.
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Options configure a Client, zero values use the defaults
+type Options struct {
+	// Timeout limits a request including all retries, default 2 minutes
+	Timeout time.Duration
+	// Retries is the number of repetitions after the first attempt,
+	// default 4, a negative value disables retries
+	Retries int
+	// Proxy overrides the proxy from HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+	Proxy string
+}
+
+const (
+	defaultTimeout = 2 * time.Minute
+	defaultRetries = 4
+)
+
+// Client performs GET requests against registries and forge apis
+type Client struct {
+	timeout   time.Duration
+	transport *RetryTransport
+}
+
+// Default is the client used by all lookups, the cli replaces it with one built
+// from its flags. Creating it can not fail as the default options set no proxy url.
+//
+//nolint:gochecknoglobals // shared by the api interfaces and providers, configured by the cli
+var Default, _ = New(Options{})
+
+// New creates a client, it fails only for an invalid proxy url
+func New(opts Options) (*Client, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.Retries == 0 {
+		opts.Retries = defaultRetries
+	} else if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if opts.Proxy != "" {
+		proxyURL, err := url.Parse(opts.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url %q: %w", opts.Proxy, err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	base := &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second,
+	}
+
+	return &Client{
+		timeout: opts.Timeout,
+		transport: &RetryTransport{
+			Base:    base,
+			Retries: opts.Retries,
+		},
+	}, nil
+}
+
+// Transport returns the retrying transport of the client for use in api clients like go-github
+func (c *Client) Transport() http.RoundTripper {
+	return c.transport
+}
+
+// Context returns a context with the timeout of a single lookup, for api clients
+// like go-github that send their requests themselves instead of through Get
+func (c *Client) Context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// Get downloads url and returns the body of a 200 OK response.
+// Errors match ErrNotFound or ErrTransient where applicable.
+func (c *Client) Get(ctx context.Context, url string, header http.Header) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	res, err := (&http.Client{Transport: c.transport}).Do(req)
+	if err != nil {
+		return nil, &NetworkError{URL: req.URL.Redacted(), Err: err}
+	}
+	defer res.Body.Close()
+
+	if err := CheckResponse(res); err != nil {
+		// drain the body so the connection can be reused
+		_, _ = io.Copy(io.Discard, res.Body)
+
+		return nil, err
+	}
+
+	return io.ReadAll(res.Body)
+}
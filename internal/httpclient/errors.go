@@ -0,0 +1,73 @@
+package httpclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	// ErrNotFound matches responses with status 404 or 410
+	ErrNotFound = errors.New("not found")
+	// ErrTransient matches failures that may succeed later: network errors,
+	// rate limits and server errors that persisted through all retries
+	ErrTransient = errors.New("transient failure")
+)
+
+// StatusError is returned for every response that is not 200 OK
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("GET %s: %s", e.URL, e.Status)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound || e.StatusCode == http.StatusGone
+	case ErrTransient:
+		return retryable(e.StatusCode)
+	}
+
+	return false
+}
+
+// NetworkError is returned when no response was received
+type NetworkError struct {
+	URL string
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("GET %s: %v", e.URL, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+func (e *NetworkError) Is(target error) bool {
+	return target == ErrTransient
+}
+
+// CheckResponse returns a StatusError unless res is 200 OK
+func CheckResponse(res *http.Response) error {
+	if res.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	return &StatusError{
+		URL:        res.Request.URL.Redacted(),
+		StatusCode: res.StatusCode,
+		Status:     res.Status,
+	}
+}
+
+// retryable reports whether a request answered with code should be repeated
+func retryable(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code != http.StatusNotImplemented)
+}
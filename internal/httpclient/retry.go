@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	baseDelay = 500 * time.Millisecond
+	maxDelay  = 30 * time.Second
+	// Retry-After values above this are not waited for, the response is returned instead
+	maxRetryAfter = 2 * time.Minute
+)
+
+// RetryTransport repeats idempotent requests that failed with a network error,
+// 429 Too Many Requests or a server error. It waits for the Retry-After header
+// if present and uses exponential backoff with full jitter otherwise.
+type RetryTransport struct {
+	Base    http.RoundTripper
+	Retries int
+}
+
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return t.Base.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+		res, err := t.Base.RoundTrip(req)
+		if attempt >= t.Retries || !shouldRetry(req.Context(), res, err) {
+			return res, err
+		}
+
+		wait := backoff(attempt)
+		if res != nil {
+			if after, ok := retryAfter(res); ok {
+				if after > maxRetryAfter {
+					return res, nil
+				}
+				wait = after
+			}
+
+			_, _ = io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+		}
+
+		log.Debug().Err(err).Msgf("Retrying %s in %s (attempt %d of %d)", req.URL.Redacted(), wait, attempt+1, t.Retries)
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+func shouldRetry(ctx context.Context, res *http.Response, err error) bool {
+	if err != nil {
+		// canceled or timed out by the caller, repeating will not help
+		return ctx.Err() == nil && !errors.Is(err, context.Canceled)
+	}
+
+	return retryable(res.StatusCode)
+}
+
+// backoff returns a random duration in [0, min(maxDelay, baseDelay*2^attempt))
+func backoff(attempt int) time.Duration {
+	delay := baseDelay << attempt
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+
+	//nolint:gosec // jitter does not need a secure random source
+	return time.Duration(rand.Int63n(int64(delay)))
+}
+
+// retryAfter parses the Retry-After header in seconds or as http date
+func retryAfter(res *http.Response) (time.Duration, bool) {
+	value := res.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,202 @@
+// Package merge updates a previously generated and manually edited document
+// with newly generated libraries
+package merge
+
+import (
+	"fmt"
+	"os"
+	"syfttoymlconverter/internal/model"
+
+	"github.com/goccy/go-yaml"
+)
+
+// field is a value of a library the merge compares. Curated fields are owned by
+// the reviewers, the others are derived from the package metadata.
+type field struct {
+	name    string
+	curated bool
+	get     func(l *model.Library) *string
+}
+
+//nolint:gochecknoglobals // constant table of the merged fields
+var fields = []field{
+	{"release", false, func(l *model.Library) *string { return &l.Release }},
+	{"libraryTable.software", false, func(l *model.Library) *string { return &l.LibraryData.Software }},
+	{"libraryTable.version", false, func(l *model.Library) *string { return &l.LibraryData.Version }},
+	{"libraryTable.license", false, func(l *model.Library) *string { return &l.LibraryData.License }},
+	{"libraryTable.manufacturer", true, func(l *model.Library) *string { return &l.LibraryData.Manufacturer }},
+	{"libraryTable.summary", true, func(l *model.Library) *string { return &l.LibraryData.Summary }},
+	{"libraryTable.function", true, func(l *model.Library) *string { return &l.LibraryData.Function }},
+	{"libraryTable.incorporated", true, func(l *model.Library) *string { return &l.LibraryData.Incorporated }},
+	{"libraryTable.levelOfConcern", true, func(l *model.Library) *string { return &l.LibraryData.LevelOfConcern }},
+	{"libraryTable.answer1", true, func(l *model.Library) *string { return &l.LibraryData.Answer1 }},
+	{"libraryTable.answer2", true, func(l *model.Library) *string { return &l.LibraryData.Answer2 }},
+	{"libraryTable.answer3", true, func(l *model.Library) *string { return &l.LibraryData.Answer3 }},
+	{"libraryTable.answer4", true, func(l *model.Library) *string { return &l.LibraryData.Answer4 }},
+	{"libraryTable.answer5", true, func(l *model.Library) *string { return &l.LibraryData.Answer5 }},
+	{"libraryTable.answer6", true, func(l *model.Library) *string { return &l.LibraryData.Answer6 }},
+}
+
+// Conflict is a field that was edited manually while the generated value changed
+type Conflict struct {
+	Library   string
+	Field     string
+	Manual    string
+	Generated string
+	// Kept is true if the manual value was kept, false if it was overwritten
+	Kept bool
+}
+
+func (c Conflict) String() string {
+	if c.Kept {
+		return fmt.Sprintf("%s: kept manual %s %q, generated value is now %q", c.Library, c.Field, c.Manual, c.Generated)
+	}
+
+	return fmt.Sprintf("%s: replaced manual %s %q with generated %q", c.Library, c.Field, c.Manual, c.Generated)
+}
+
+// Result is the merged list of libraries
+type Result struct {
+	Libraries []model.Library
+	Conflicts []Conflict
+	Added     int
+	Updated   int
+	Removed   int
+}
+
+// Merge merges the generated libraries into the existing ones. Libraries are keyed
+// by source and submodule. base are the libraries generated by the previous run,
+// they tell which fields were edited manually. Without base every curated field
+// that differs from the generated value counts as edited.
+//
+// Edited curated fields are kept, all other fields take the generated value.
+// If the generated value changed under an edited field it is reported as conflict.
+// Existing libraries that were not generated again are kept and marked as removed.
+func Merge(existing, base, generated []model.Library) Result {
+	var result Result
+
+	bases := index(base)
+	fresh := index(generated)
+	done := map[*model.Library]bool{}
+
+	for i := range existing {
+		lib := existing[i]
+		key := key(lib)
+
+		gen := fresh.next(key)
+		if gen == nil {
+			if !lib.Removed {
+				lib.Removed = true
+				result.Removed++
+			}
+			result.Libraries = append(result.Libraries, lib)
+			continue
+		}
+		done[gen] = true
+
+		changed := mergeLibrary(&lib, bases.next(key), gen, &result)
+		if changed || lib.Removed {
+			result.Updated++
+		}
+		lib.Removed = false
+		result.Libraries = append(result.Libraries, lib)
+	}
+
+	for i := range generated {
+		if !done[&generated[i]] {
+			result.Libraries = append(result.Libraries, generated[i])
+			result.Added++
+		}
+	}
+
+	return result
+}
+
+// mergeLibrary merges the fields of gen into lib and reports whether lib changed
+func mergeLibrary(lib, base, gen *model.Library, result *Result) bool {
+	changed := false
+	for _, f := range fields {
+		ours, theirs := f.get(lib), *f.get(gen)
+		if *ours == theirs {
+			continue
+		}
+
+		var edited, generatedChanged bool
+		if base != nil {
+			edited = *ours != *f.get(base)
+			generatedChanged = theirs != *f.get(base)
+		} else {
+			edited = f.curated
+		}
+
+		switch {
+		case !edited:
+			*ours = theirs
+			changed = true
+		case f.curated:
+			if generatedChanged {
+				result.Conflicts = append(result.Conflicts, Conflict{
+					Library: key(*lib), Field: f.name, Manual: *ours, Generated: theirs, Kept: true,
+				})
+			}
+		default:
+			result.Conflicts = append(result.Conflicts, Conflict{
+				Library: key(*lib), Field: f.name, Manual: *ours, Generated: theirs,
+			})
+			*ours = theirs
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func key(l model.Library) string {
+	if l.Submodule == "" {
+		return l.Source
+	}
+
+	return l.Source + " (" + l.Submodule + ")"
+}
+
+// libraries by key, several versions of a package can share one
+type libraryIndex map[string][]*model.Library
+
+func index(libs []model.Library) libraryIndex {
+	idx := libraryIndex{}
+	for i := range libs {
+		k := key(libs[i])
+		idx[k] = append(idx[k], &libs[i])
+	}
+
+	return idx
+}
+
+// next returns the next unused library with key
+func (idx libraryIndex) next(key string) *model.Library {
+	libs := idx[key]
+	if len(libs) == 0 {
+		return nil
+	}
+	idx[key] = libs[1:]
+
+	return libs[0]
+}
+
+// Read reads the libraries of a document, ok is false if the file does not exist
+func Read(path string) ([]model.Library, bool, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var libs model.Librarys
+	if err := yaml.Unmarshal(data, &libs); err != nil {
+		return nil, false, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return libs.Libraries, true, nil
+}
@@ -17,8 +17,27 @@ type Module struct {
 	SubPath string
 	Version string
 	Hash    string
+	// Ecosystem is the handler that resolved the module: npm, go, dotnet or conan
+	Ecosystem string
+	// Scope is the dependency scope like dev, test or the CycloneDX excluded if the SBOM records it
+	Scope string
+	// Purl is the package url of the artifact
+	Purl string
+	// Hashes are the hex encoded checksums of the package by algorithm, e.g. SHA-512
+	Hashes map[string]string
+	// Parents are the names of the packages that directly depend on the module
 	Parents []string
-	Info    RepoInfo
+	// ParentIDs are the artifact ids (Hash) of the modules that directly depend on the module
+	ParentIDs []string
+	// Direct is true if the project itself depends on the module
+	Direct bool
+	// Depth is the shortest distance to the project, 1 for direct dependencies
+	Depth int
+	Info  RepoInfo
+	// Repository is the source code repository as host and path, if the registry names one
+	Repository string
+	// Unresolved lists the documents that were missing in offline mode
+	Unresolved []string
 }
 
 func (m Module) String() string {
@@ -14,6 +14,8 @@ type Dependency struct {
 	Language   string
 	//Not sure if we should keep purl for tool independency
 	Purl string
+	// Scope is the dependency scope like dev, test or the CycloneDX excluded if the SBOM records it
+	Scope string
 	//Url      string
 	TopLevel bool
 }
@@ -1,25 +1,15 @@
 package model
 
 import (
-	"fmt"
 	"strings"
 )
 
-const (
-	answer1fmt = "Manufacturer: %s\nVersion: %s\nLicense: %s\n\n%s"
-	answer2    = "The hardware specification is documented in [DPS].\nThe software specification is outlined in [SRS]."
-	answer3    = "Software Requirements are captured in [SRS]. Software Tests are described in [SVP] and [VTP]. Traceability is ensured by [EVDR].\n\n(1) The OTS SW was incorporated in the device during installation of the system. There is no possibility to see, remove or change the system files."
-	answer4Fmt = "The software is needed as dependency of %s.\n\nThere are no specialized requirements defined for this component. Requirements for the system are specified in [SRS].\n\nThe OTS SW does not link with software outside the system."
-	answer5    = "Software Tests are described in [SVP] and [VTP].\n\nThe OTS SW is incorporated in the device during installation of the system. There is no possibility to see, remove or change the system files."
-	answer6    = "The OTS SW is incorporated in the device during installation of the system and it will be ensured, that the user can not see, remove or change the system files.\nConfiguration and Version of the OTS is kept under version control in Git\n\nThe lifecycle of the OTS will be maintained using the [FOSS] process."
-)
-
 // Document is the main structure for generating the files
 type Document struct {
 	Header              Header              `validate:"required" yaml:"header"`
 	FrontPage           FrontPage           `validate:"required" yaml:"frontPage"`
 	ReferencedDocuments ReferencedDocuments `validate:"required" yaml:"referenceDocuments"`
-	Libraries           []Library           `validate:"required" yaml:"libraries"`
+	Libraries           []Library           `validate:"required,dive" yaml:"libraries"`
 }
 
 // Header has the specified information about the document itself
@@ -69,7 +59,7 @@ type ReferencedDocuments struct {
 }
 
 type Librarys struct {
-	Libraries []Library `json:"libraries"`
+	Libraries []Library `validate:"required,dive" json:"libraries"`
 }
 
 // Library structure
@@ -78,6 +68,20 @@ type Library struct {
 	Submodule   string            `yaml:"submodule"`
 	Release     string            `validate:"required" yaml:"release"`
 	LibraryData TableMainTemplate `validate:"required" yaml:"libraryTable"`
+	// Removed marks libraries of a merged document that are no longer in the SBOM
+	Removed bool `yaml:"removed,omitempty"`
+}
+
+// Name identifies the library in messages
+func (l Library) Name() string {
+	if l.LibraryData.Software == "" {
+		return l.Source
+	}
+	if l.LibraryData.Version == "" {
+		return l.LibraryData.Software
+	}
+
+	return l.LibraryData.Software + " " + l.LibraryData.Version
 }
 
 // TableMainTemplate contains the content of a library table
@@ -98,12 +102,11 @@ type TableMainTemplate struct {
 	Answer6        string `validate:"required" yaml:"answer6"`
 }
 
+// ModelToLibrary converts every module into a library in the same order.
+// The answers are left empty, they are rendered from templates.
 func ModelToLibrary(info *BuildInfo) Librarys {
 	libs := Librarys{Libraries: []Library{}}
 	for _, d := range info.Modules {
-		if len(d.Parents) > 3 {
-			continue
-		}
 		var lib Library
 		lib.Source = d.Path
 		lib.Submodule = d.SubPath
@@ -123,23 +126,6 @@ func ModelToLibrary(info *BuildInfo) Librarys {
 		lib.LibraryData.Software = s[len(s)-1]
 
 		// default values
-		lib.LibraryData.Answer1 = fmt.Sprintf(
-			answer1fmt,
-			lib.LibraryData.Manufacturer,
-			lib.LibraryData.Version,
-			lib.LibraryData.License,
-			lib.LibraryData.Summary,
-		)
-
-		if len(d.Parents) > 0 {
-			lib.LibraryData.Answer4 = fmt.Sprintf(answer4Fmt, strings.Join(d.Parents, ", "))
-		}
-
-		lib.LibraryData.Answer2 = answer2
-		lib.LibraryData.Answer3 = answer3
-		lib.LibraryData.Answer5 = answer5
-		lib.LibraryData.Answer6 = answer6
-
 		lib.LibraryData.Incorporated = "Yes"
 		lib.LibraryData.LevelOfConcern = "Minor"
 		lib.LibraryData.Function = "Library"
@@ -0,0 +1,181 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"syfttoymlconverter/internal/httpclient"
+	"syfttoymlconverter/internal/model"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// number of repositories queried in one graphql request
+const graphqlBatchSize = 50
+
+type graphqlResponse struct {
+	Data   map[string]*graphqlRepository `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type graphqlRepository struct {
+	NameWithOwner string `json:"nameWithOwner"`
+	Description   string `json:"description"`
+	LicenseInfo   *struct {
+		SpdxID string `json:"spdxId"`
+	} `json:"licenseInfo"`
+	Release *struct {
+		PublishedAt *time.Time `json:"publishedAt"`
+	} `json:"release"`
+	Ref *struct {
+		Target graphqlTarget `json:"target"`
+	} `json:"ref"`
+}
+
+// graphqlTarget is a commit or an annotated tag pointing to a commit
+type graphqlTarget struct {
+	AuthoredDate *time.Time `json:"authoredDate"`
+	Target       *struct {
+		AuthoredDate *time.Time `json:"authoredDate"`
+	} `json:"target"`
+}
+
+// Prefetch looks up the repository data and release dates of many paths with
+// batched graphql queries, Info then answers from the prefetched results
+func (g *githubProvider) Prefetch(lookups []RepoRef) {
+	if g.http == nil {
+		log.Warn().Msgf("Batched lookups on %s need a token, falling back to single requests", g.host)
+
+		return
+	}
+
+	var batch []RepoRef
+	seen := map[string]bool{}
+	for _, l := range lookups {
+		if _, _, ok := g.split(l.Path); !ok || seen[l.Path+"@"+l.Tag] {
+			continue
+		}
+		seen[l.Path+"@"+l.Tag] = true
+
+		batch = append(batch, l)
+		if len(batch) == graphqlBatchSize {
+			g.prefetchBatch(batch)
+			batch = nil
+		}
+	}
+	if len(batch) > 0 {
+		g.prefetchBatch(batch)
+	}
+}
+
+func (g *githubProvider) prefetchBatch(batch []RepoRef) {
+	log.Info().Msgf("Fetching %d repositories from %s with one graphql query", len(batch), g.host)
+
+	result, err := g.queryGraphql(graphqlQuery(g, batch))
+	if err != nil {
+		log.Warn().Err(err).Msgf("Graphql query on %s failed, falling back to single requests", g.host)
+
+		return
+	}
+
+	for i, l := range batch {
+		repo := result.Data[fmt.Sprintf("r%d", i)]
+		if repo == nil {
+			// not found, Info reports it with a single request
+			continue
+		}
+
+		info := model.RepoInfo{
+			FullName:    repo.NameWithOwner,
+			Description: repo.Description,
+		}
+		if repo.LicenseInfo != nil && repo.LicenseInfo.SpdxID != "NOASSERTION" {
+			info.SPDX = repo.LicenseInfo.SpdxID
+		}
+		switch {
+		case repo.Release != nil && repo.Release.PublishedAt != nil:
+			info.Release = repo.Release.PublishedAt.Local()
+		case repo.Ref != nil && repo.Ref.Target.AuthoredDate != nil:
+			info.Release = repo.Ref.Target.AuthoredDate.Local()
+		case repo.Ref != nil && repo.Ref.Target.Target != nil && repo.Ref.Target.Target.AuthoredDate != nil:
+			info.Release = repo.Ref.Target.Target.AuthoredDate.Local()
+		}
+
+		g.prefetched.Store(l.Path+"@"+l.Tag, info)
+	}
+}
+
+// graphqlQuery builds one aliased repository field per lookup: r0, r1, ...
+func graphqlQuery(g *githubProvider, batch []RepoRef) string {
+	var b strings.Builder
+	b.WriteString("query {\n")
+	for i, l := range batch {
+		owner, reponame, _ := g.split(l.Path)
+		fmt.Fprintf(&b, "  r%d: repository(owner: %s, name: %s) {\n", i, graphqlString(owner), graphqlString(reponame))
+		b.WriteString("    nameWithOwner\n    description\n    licenseInfo { spdxId }\n")
+		if l.Tag != "" {
+			fmt.Fprintf(&b, "    release(tagName: %s) { publishedAt }\n", graphqlString(l.Tag))
+			fmt.Fprintf(&b, "    ref(qualifiedName: %s) { target { ... on Commit { authoredDate } "+
+				"... on Tag { target { ... on Commit { authoredDate } } } } }\n", graphqlString("refs/tags/"+l.Tag))
+		}
+		b.WriteString("  }\n")
+	}
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// graphqlString quotes s, graphql uses the json string syntax
+func graphqlString(s string) string {
+	quoted, _ := json.Marshal(s)
+
+	return string(quoted)
+}
+
+func (g *githubProvider) queryGraphql(query string) (*graphqlResponse, error) {
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := httpclient.Default.Context()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.graphqlURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := g.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err := httpclient.CheckResponse(res); err != nil {
+		return nil, err
+	}
+
+	var result graphqlResponse
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "failed to decode graphql response")
+	}
+	if result.Data == nil && len(result.Errors) > 0 {
+		return nil, errors.New(result.Errors[0].Message)
+	}
+
+	// errors for single repositories (NOT_FOUND, ...) are expected
+	for _, e := range result.Errors {
+		log.Debug().Msgf("Graphql error on %s: %s", g.host, e.Message)
+	}
+
+	return &result, nil
+}
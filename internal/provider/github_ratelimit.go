@@ -0,0 +1,207 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimitOptions control how the github providers spend their request quota
+type RateLimitOptions struct {
+	// Reserve is the number of requests kept back, lookups pause once the quota drops to it
+	Reserve int
+	// MaxWait is the longest pause until the quota resets, lookups are skipped beyond it
+	MaxWait time.Duration
+	// SlowDownBelow is the fraction of the limit below which requests are spread
+	// evenly over the time left until the reset
+	SlowDownBelow float64
+}
+
+// DefaultRateLimitOptions keep 10 requests back, wait up to 15 minutes for a reset
+// and slow down below 10% of the quota
+var DefaultRateLimitOptions = RateLimitOptions{
+	Reserve:       10,
+	MaxWait:       15 * time.Minute,
+	SlowDownBelow: 0.1,
+}
+
+var (
+	rateLimitOptions = DefaultRateLimitOptions
+
+	skippedMu sync.Mutex
+	skipped   []string
+)
+
+// SetRateLimitOptions has to be called before the first lookup
+func SetRateLimitOptions(opts RateLimitOptions) {
+	rateLimitOptions = opts
+}
+
+// Skipped returns the requests that were not sent because the quota of a host was exhausted
+func Skipped() []string {
+	skippedMu.Lock()
+	defer skippedMu.Unlock()
+
+	result := append([]string(nil), skipped...)
+	sort.Strings(result)
+
+	return result
+}
+
+// RateLimitError is returned for requests skipped because the quota is exhausted
+type RateLimitError struct {
+	URL   string
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("skipped %s: rate limit exhausted until %s", e.URL, e.Reset.Format(time.Kitchen))
+}
+
+// quota is the state of one rate limit resource ("core", "graphql", ...) as reported
+// by the X-RateLimit headers of the last response
+type quota struct {
+	known     bool
+	limit     int
+	remaining int
+	reset     time.Time
+	// paused is set once the pause until reset was announced
+	paused bool
+}
+
+// rateBudget tracks the quota of a github host and delays or skips requests before it runs out
+type rateBudget struct {
+	host string
+	base http.RoundTripper
+	opts RateLimitOptions
+
+	mu     sync.Mutex
+	quotas map[string]*quota
+}
+
+func newRateBudget(host string, base http.RoundTripper) *rateBudget {
+	return &rateBudget{
+		host:   host,
+		base:   base,
+		opts:   rateLimitOptions,
+		quotas: map[string]*quota{},
+	}
+}
+
+func (b *rateBudget) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource := resourceOf(req)
+
+	wait, err := b.reserve(req, resource)
+	if err != nil {
+		return nil, err
+	}
+	if wait > 0 {
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(wait):
+		}
+	}
+
+	res, err := b.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b.update(res, resource)
+
+	return res, nil
+}
+
+// reserve takes one request from the quota and returns how long to wait before sending it
+func (b *rateBudget) reserve(req *http.Request, resource string) (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q, ok := b.quotas[resource]
+	if !ok || !q.known {
+		return 0, nil
+	}
+
+	now := time.Now()
+	if now.After(q.reset) {
+		// the window has reset, the next response reports the new quota
+		q.known = false
+		return 0, nil
+	}
+
+	untilReset := q.reset.Sub(now)
+	if q.remaining <= b.opts.Reserve {
+		if untilReset > b.opts.MaxWait {
+			return 0, skip(req, q.reset)
+		}
+
+		if !q.paused {
+			log.Warn().Msgf("GitHub %s quota of %s exhausted, pausing %s until it resets", resource, b.host, untilReset.Round(time.Second))
+			q.paused = true
+		}
+
+		return untilReset, nil
+	}
+
+	var wait time.Duration
+	if float64(q.remaining) < float64(q.limit)*b.opts.SlowDownBelow {
+		// spread the remaining requests over the rest of the window
+		wait = untilReset / time.Duration(q.remaining-b.opts.Reserve)
+		if wait > b.opts.MaxWait {
+			return 0, skip(req, q.reset)
+		}
+	}
+	q.remaining--
+
+	return wait, nil
+}
+
+// skip records req as skipped lookup
+func skip(req *http.Request, reset time.Time) error {
+	skippedMu.Lock()
+	skipped = append(skipped, fmt.Sprintf("%s %s", req.Method, req.URL.Redacted()))
+	skippedMu.Unlock()
+
+	return &RateLimitError{URL: req.URL.Redacted(), Reset: reset}
+}
+
+func (b *rateBudget) update(res *http.Response, resource string) {
+	remaining, err := strconv.Atoi(res.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	limit, _ := strconv.Atoi(res.Header.Get("X-RateLimit-Limit"))
+	reset, _ := strconv.ParseInt(res.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if r := res.Header.Get("X-RateLimit-Resource"); r != "" {
+		resource = r
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.quotas[resource] = &quota{
+		known:     true,
+		limit:     limit,
+		remaining: remaining,
+		reset:     time.Unix(reset, 0),
+	}
+}
+
+// resourceOf guesses the rate limit resource of a request before the response names it
+func resourceOf(req *http.Request) string {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		return "graphql"
+	case strings.Contains(req.URL.Path, "/search/"):
+		return "search"
+	}
+
+	return "core"
+}
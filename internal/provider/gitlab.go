@@ -0,0 +1,288 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"syfttoymlconverter/internal/cache"
+	"syfttoymlconverter/internal/httpclient"
+	"syfttoymlconverter/internal/model"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// spdx ids of the license keys gitlab reports for a project, gitlab detects the
+// licenses with licensee and uses its keys. The GNU licenses map to the -only ids,
+// the plain ids are deprecated.
+var gitlabLicenses = map[string]string{
+	"0bsd":               "0BSD",
+	"afl-3.0":            "AFL-3.0",
+	"agpl-3.0":           "AGPL-3.0-only",
+	"apache-2.0":         "Apache-2.0",
+	"artistic-2.0":       "Artistic-2.0",
+	"bsd-2-clause":       "BSD-2-Clause",
+	"bsd-3-clause":       "BSD-3-Clause",
+	"bsd-3-clause-clear": "BSD-3-Clause-Clear",
+	"bsd-4-clause":       "BSD-4-Clause",
+	"bsl-1.0":            "BSL-1.0",
+	"cc-by-4.0":          "CC-BY-4.0",
+	"cc-by-sa-4.0":       "CC-BY-SA-4.0",
+	"cc0-1.0":            "CC0-1.0",
+	"ecl-2.0":            "ECL-2.0",
+	"epl-1.0":            "EPL-1.0",
+	"epl-2.0":            "EPL-2.0",
+	"eupl-1.1":           "EUPL-1.1",
+	"eupl-1.2":           "EUPL-1.2",
+	"gpl-2.0":            "GPL-2.0-only",
+	"gpl-3.0":            "GPL-3.0-only",
+	"isc":                "ISC",
+	"lgpl-2.1":           "LGPL-2.1-only",
+	"lgpl-3.0":           "LGPL-3.0-only",
+	"lppl-1.3c":          "LPPL-1.3c",
+	"mit":                "MIT",
+	"mit-0":              "MIT-0",
+	"mpl-2.0":            "MPL-2.0",
+	"ms-pl":              "MS-PL",
+	"ms-rl":              "MS-RL",
+	"mulanpsl-2.0":       "MulanPSL-2.0",
+	"ncsa":               "NCSA",
+	"odbl-1.0":           "ODbL-1.0",
+	"ofl-1.1":            "OFL-1.1",
+	"osl-3.0":            "OSL-3.0",
+	"postgresql":         "PostgreSQL",
+	"unlicense":          "Unlicense",
+	"upl-1.0":            "UPL-1.0",
+	"vim":                "Vim",
+	"wtfpl":              "WTFPL",
+	"zlib":               "Zlib",
+}
+
+// license files checked in the repository, in order
+var gitlabLicenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "COPYING"}
+
+type gitlabProvider struct {
+	host    string
+	baseURL string // e.g. https://gitlab.com/api/v4
+	token   string
+	client  *http.Client
+}
+
+type gitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	Description       string `json:"description"`
+	License           *struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"license"`
+}
+
+type gitlabRelease struct {
+	ReleasedAt *time.Time `json:"released_at"`
+	CreatedAt  *time.Time `json:"created_at"`
+}
+
+type gitlabTag struct {
+	Commit gitlabCommit `json:"commit"`
+}
+
+type gitlabCommit struct {
+	CommittedDate *time.Time `json:"committed_date"`
+}
+
+type gitlabLicenseTemplate struct {
+	Content string `json:"content"`
+}
+
+// newGitlab creates a provider for host, an empty baseURL uses https://<host>/api/v4
+func newGitlab(host, baseURL, token string) *gitlabProvider {
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("https://%s/api/v4", host)
+	}
+	if token == "" {
+		log.Warn().Msgf("No GitLab token found for %s, only public projects can be looked up", host)
+	}
+
+	return &gitlabProvider{
+		host:    host,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		// the timeout of --http-timeout is set per request
+		client: &http.Client{
+			Transport: cache.Default.Transport(httpclient.Default.Transport(), token),
+		},
+	}
+}
+
+func (g *gitlabProvider) Info(path, tag string) (model.RepoInfo, error) {
+	project, ok := g.project(path)
+	if !ok {
+		return model.RepoInfo{}, errors.Errorf("pattern missmatch, no gitlab source [%s]", path)
+	}
+
+	info := model.RepoInfo{}
+
+	var data gitlabProject
+	err := g.get(fmt.Sprintf("/projects/%s?license=true", project), &data)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to fetch repository data for %s", path)
+		err = errors.Wrapf(err, "failed to fetch repository data for %s", path)
+	} else {
+		info.FullName = data.PathWithNamespace
+		info.Description = data.Description
+		if data.License != nil {
+			info.SPDX = gitlabLicense(data.License.Key)
+		}
+	}
+
+	// without a tag the caller already knows the release date
+	if tag != "" {
+		if releaseDate, ok := g.ReleaseDate(path, tag); ok {
+			info.Release = releaseDate
+		}
+	}
+
+	return info, err
+}
+
+func (g *gitlabProvider) ReleaseDate(path, tag string) (time.Time, bool) {
+	project, ok := g.project(path)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	var release gitlabRelease
+	err := g.get(fmt.Sprintf("/projects/%s/releases/%s", project, url.PathEscape(tag)), &release)
+	if err == nil {
+		if release.ReleasedAt != nil {
+			return release.ReleasedAt.Local(), true
+		}
+		if release.CreatedAt != nil {
+			return release.CreatedAt.Local(), true
+		}
+	}
+	log.Debug().Err(err).Msgf("Failed to fetch release date by release for %s@%s", path, tag)
+
+	var repoTag gitlabTag
+	err = g.get(fmt.Sprintf("/projects/%s/repository/tags/%s", project, url.PathEscape(tag)), &repoTag)
+	if err == nil && repoTag.Commit.CommittedDate != nil {
+		return repoTag.Commit.CommittedDate.Local(), true
+	}
+	log.Debug().Err(err).Msgf("Failed to fetch release date by tag for %s@%s", path, tag)
+
+	// the version may name a commit or branch instead of a tag, like go pseudo versions
+	var commit gitlabCommit
+	err = g.get(fmt.Sprintf("/projects/%s/repository/commits/%s", project, url.PathEscape(tag)), &commit)
+	if err == nil && commit.CommittedDate != nil {
+		return commit.CommittedDate.Local(), true
+	}
+	log.Debug().Err(err).Msgf("Failed to fetch release date for %s@%s", path, tag)
+
+	return time.Time{}, false
+}
+
+func (g *gitlabProvider) LicenseText(path, spdx string) (string, error) {
+	project, ok := g.project(path)
+	if !ok {
+		return "", errors.Errorf("pattern missmatch, no gitlab source [%s]", path)
+	}
+
+	for _, file := range gitlabLicenseFiles {
+		text, err := g.raw(fmt.Sprintf("/projects/%s/repository/files/%s/raw?ref=HEAD", project, url.PathEscape(file)))
+		if err == nil {
+			return text, nil
+		}
+	}
+
+	// no license file in repo, return general license text
+	var template gitlabLicenseTemplate
+	if err := g.get("/templates/licenses/"+url.PathEscape(gitlabLicenseKey(spdx)), &template); err != nil {
+		return "", errors.Wrap(err, "failed to fetch license template from gitlab")
+	}
+
+	return template.Content, nil
+}
+
+// gitlabLicense returns the spdx id of a gitlab license key, empty for unknown
+// keys so the license is looked up elsewhere instead of guessing an id
+func gitlabLicense(key string) string {
+	if spdx, ok := gitlabLicenses[key]; ok {
+		return spdx
+	}
+	if key != "" && key != "other" {
+		log.Warn().Msgf("Unknown GitLab license key %q, the license is left empty", key)
+	}
+
+	return ""
+}
+
+// gitlabLicenseKey returns the gitlab license key of an spdx id
+func gitlabLicenseKey(spdx string) string {
+	for key, id := range gitlabLicenses {
+		if strings.EqualFold(id, spdx) {
+			return key
+		}
+	}
+
+	return strings.ToLower(spdx)
+}
+
+// project returns the url encoded project id of a path like gitlab.com/group/subgroup/repo
+func (g *gitlabProvider) project(path string) (string, bool) {
+	prefix := g.host + "/"
+	if !strings.HasPrefix(strings.ToLower(path), prefix) {
+		return "", false
+	}
+
+	project := strings.TrimSuffix(path[len(prefix):], ".git")
+	if strings.Count(project, "/") < 1 {
+		return "", false
+	}
+
+	return url.PathEscape(project), true
+}
+
+func (g *gitlabProvider) get(endpoint string, v interface{}) error {
+	body, err := g.request(endpoint)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func (g *gitlabProvider) raw(endpoint string) (string, error) {
+	body, err := g.request(endpoint)
+
+	return string(body), err
+}
+
+func (g *gitlabProvider) request(endpoint string) ([]byte, error) {
+	ctx, cancel := httpclient.Default.Context()
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if g.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", g.token)
+	}
+
+	res, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if err := httpclient.CheckResponse(res); err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(res.Body)
+}
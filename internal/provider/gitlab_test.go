@@ -0,0 +1,244 @@
+package provider
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"syfttoymlconverter/internal/cache"
+	"syfttoymlconverter/internal/httpclient"
+)
+
+// newTestGitlab returns a provider for gitlab.example.com answered by routes, keyed by
+// the escaped request path. Unknown paths are answered with 404.
+func newTestGitlab(t *testing.T, routes map[string]string) (*gitlabProvider, *[]string) {
+	t.Helper()
+
+	var requests []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.EscapedPath())
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			http.Error(w, "missing token", http.StatusUnauthorized)
+			return
+		}
+		body, ok := routes[r.URL.EscapedPath()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	defaultCache := cache.Default
+	cache.Default = &cache.Cache{Disabled: true}
+	t.Cleanup(func() { cache.Default = defaultCache })
+
+	return newGitlab("gitlab.example.com", srv.URL+"/api/v4/", "tok"), &requests
+}
+
+func TestGitlabInfo(t *testing.T) {
+	g, _ := newTestGitlab(t, map[string]string{
+		"/api/v4/projects/group%2Fsub%2Frepo": `{
+			"path_with_namespace": "group/sub/repo",
+			"description": "a nested project",
+			"license": {"key": "apache-2.0", "name": "Apache License 2.0"}
+		}`,
+		"/api/v4/projects/group%2Fsub%2Frepo/releases/v1.2.0": `{"released_at": "2023-04-05T06:07:08Z"}`,
+	})
+
+	info, err := g.Info("gitlab.example.com/group/sub/repo.git", "v1.2.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.FullName != "group/sub/repo" || info.Description != "a nested project" || info.SPDX != "Apache-2.0" {
+		t.Errorf("unexpected info %+v", info)
+	}
+	if want := time.Date(2023, 4, 5, 6, 7, 8, 0, time.UTC); !info.Release.Equal(want) {
+		t.Errorf("release = %v, want %v", info.Release, want)
+	}
+}
+
+func TestGitlabInfoUnknownLicense(t *testing.T) {
+	g, requests := newTestGitlab(t, map[string]string{
+		"/api/v4/projects/group%2Frepo": `{"path_with_namespace": "group/repo", "license": {"key": "custom-license"}}`,
+	})
+
+	info, err := g.Info("gitlab.example.com/group/repo", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.SPDX != "" {
+		t.Errorf("SPDX = %q, want no license for an unknown key", info.SPDX)
+	}
+	if len(*requests) != 1 {
+		t.Errorf("expected no release lookup without tag, got requests %v", *requests)
+	}
+}
+
+func TestGitlabLicense(t *testing.T) {
+	tests := map[string]string{
+		"mit":            "MIT",
+		"0bsd":           "0BSD",
+		"gpl-2.0":        "GPL-2.0-only",
+		"lgpl-2.1":       "LGPL-2.1-only",
+		"agpl-3.0":       "AGPL-3.0-only",
+		"other":          "",
+		"":               "",
+		"custom-license": "",
+	}
+	for key, want := range tests {
+		if got := gitlabLicense(key); got != want {
+			t.Errorf("gitlabLicense(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestGitlabInfoNotFound(t *testing.T) {
+	g, _ := newTestGitlab(t, map[string]string{})
+
+	info, err := g.Info("gitlab.example.com/group/missing", "v1.0.0")
+	var statusErr *httpclient.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 status error, got %v", err)
+	}
+	if info.FullName != "" || !info.Release.IsZero() {
+		t.Errorf("expected empty info, got %+v", info)
+	}
+}
+
+func TestGitlabInfoNoGitlabPath(t *testing.T) {
+	g, requests := newTestGitlab(t, map[string]string{})
+
+	for _, path := range []string{"github.com/group/repo", "gitlab.example.com/repo"} {
+		if _, err := g.Info(path, "v1.0.0"); err == nil {
+			t.Errorf("expected an error for %s", path)
+		}
+	}
+	if len(*requests) != 0 {
+		t.Errorf("expected no requests, got %v", *requests)
+	}
+}
+
+func TestGitlabReleaseDate(t *testing.T) {
+	const project = "/api/v4/projects/group%2Fsub%2Frepo"
+	tests := []struct {
+		name   string
+		routes map[string]string
+		want   time.Time
+		ok     bool
+	}{
+		{
+			name: "release",
+			routes: map[string]string{
+				project + "/releases/v1.0.0":        `{"released_at": "2021-01-02T03:04:05Z", "created_at": "2020-01-01T00:00:00Z"}`,
+				project + "/repository/tags/v1.0.0": `{"commit": {"committed_date": "2019-01-01T00:00:00Z"}}`,
+			},
+			want: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			ok:   true,
+		},
+		{
+			name: "release without released_at",
+			routes: map[string]string{
+				project + "/releases/v1.0.0": `{"created_at": "2020-01-01T00:00:00Z"}`,
+			},
+			want: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			ok:   true,
+		},
+		{
+			name: "tag",
+			routes: map[string]string{
+				project + "/repository/tags/v1.0.0":    `{"commit": {"committed_date": "2019-01-01T10:00:00+02:00"}}`,
+				project + "/repository/commits/v1.0.0": `{"committed_date": "2018-01-01T00:00:00Z"}`,
+			},
+			want: time.Date(2019, 1, 1, 8, 0, 0, 0, time.UTC),
+			ok:   true,
+		},
+		{
+			name: "commit",
+			routes: map[string]string{
+				project + "/repository/commits/v1.0.0": `{"committed_date": "2018-01-01T00:00:00Z"}`,
+			},
+			want: time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+			ok:   true,
+		},
+		{
+			name:   "not found",
+			routes: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGitlab(t, tt.routes)
+
+			got, ok := g.ReleaseDate("gitlab.example.com/group/sub/repo", "v1.0.0")
+			if ok != tt.ok || !got.Equal(tt.want) {
+				t.Errorf("ReleaseDate() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+			if ok && got.Location() != time.Local {
+				t.Errorf("expected the release date in local time, got %v", got.Location())
+			}
+		})
+	}
+}
+
+func TestGitlabLicenseText(t *testing.T) {
+	const project = "/api/v4/projects/group%2Fsub%2Frepo"
+	tests := []struct {
+		name    string
+		spdx    string
+		routes  map[string]string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "license file",
+			routes: map[string]string{
+				project + "/repository/files/LICENSE.md/raw": "MIT License of the project",
+				"/api/v4/templates/licenses/mit":             `{"content": "MIT License template"}`,
+			},
+			want: "MIT License of the project",
+		},
+		{
+			name: "template",
+			routes: map[string]string{
+				"/api/v4/templates/licenses/mit": `{"content": "MIT License template"}`,
+			},
+			want: "MIT License template",
+		},
+		{
+			name: "template of a gnu license",
+			spdx: "GPL-2.0-only",
+			routes: map[string]string{
+				"/api/v4/templates/licenses/gpl-2.0": `{"content": "GNU GENERAL PUBLIC LICENSE Version 2"}`,
+			},
+			want: "GNU GENERAL PUBLIC LICENSE Version 2",
+		},
+		{
+			name:    "not found",
+			routes:  map[string]string{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, _ := newTestGitlab(t, tt.routes)
+
+			spdx := tt.spdx
+			if spdx == "" {
+				spdx = "MIT"
+			}
+			got, err := g.LicenseText("gitlab.example.com/group/sub/repo", spdx)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LicenseText() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("LicenseText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/goccy/go-yaml"
+	"github.com/rs/zerolog/log"
+)
+
+// HostConfig configures a self-hosted forge in the hosts file:
+//
+//	hosts:
+//	  github.company.com:
+//	    type: github
+//	    api: https://github.company.com/api/v3/
+//	  gitlab.company.com:
+//	    type: gitlab
+//	    api: https://gitlab.company.com/api/v4
+//	    token: glpat-...
+//
+// The token is resolved through the credential source like for every other host.
+type HostConfig struct {
+	Type string `yaml:"type"`
+	API  string `yaml:"api"`
+}
+
+// LoadHosts registers a provider for every host with a type in the hosts file.
+// A missing file is not an error.
+func LoadHosts(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	var file struct {
+		Hosts map[string]HostConfig `yaml:"hosts"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	for host, config := range file.Hosts {
+		if err := RegisterHost(host, config); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+// RegisterHost registers the provider matching the type of config for host
+func RegisterHost(host string, config HostConfig) error {
+	switch config.Type {
+	case "":
+		// only a token for a host registered by default
+		return nil
+	case "github":
+		Register(host, githubFactory(config.API))
+	case "gitlab":
+		api := config.API
+		Register(host, func(host string) Provider {
+			token, _ := credentialSource.Token(host)
+
+			return newGitlab(host, api, token)
+		})
+	default:
+		return fmt.Errorf("unknown type %q of host %s", config.Type, host)
+	}
+
+	return nil
+}
+
+// githubFactory creates github providers using the api at baseURL, see newGithub
+func githubFactory(baseURL string) Factory {
+	return func(host string) Provider {
+		token, _ := credentialSource.Token(host)
+
+		g, err := newGithub(host, baseURL, token)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to create github provider for %s", host)
+
+			return nil
+		}
+
+		return g
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"syfttoymlconverter/internal/cache"
 	"syfttoymlconverter/internal/model"
 
 	"github.com/rs/zerolog/log"
@@ -13,13 +14,69 @@ import (
 // is the regexp matching the package for a golang import
 var golangRegEx = regexp.MustCompile(`^golang\.org\/x\/(.+)$`)
 
+// RepoRef is a repository path and the tag whose release date is needed
+type RepoRef struct {
+	Path string
+	Tag  string
+}
+
+// Prefetcher is implemented by providers that can look up many repositories at once
+type Prefetcher interface {
+	Prefetch(lookups []RepoRef)
+}
+
+//nolint:gochecknoglobals // set by the cli
+var batchingEnabled bool
+
+// EnableBatching lets PrefetchModuleInfo use batched queries like the github graphql api
+func EnableBatching(enabled bool) {
+	batchingEnabled = enabled
+}
+
+// PrefetchModuleInfo looks up all modules with batched queries where the provider
+// supports it, so the following FetchModuleInfo calls need no further requests.
+// Without EnableBatching or in offline mode it does nothing.
+func PrefetchModuleInfo(modules []model.Module) {
+	if !batchingEnabled || cache.Default.Offline {
+		return
+	}
+
+	byProvider := map[Prefetcher][]RepoRef{}
+	for _, m := range modules {
+		source, tag := normalize(m.Path, m.Version)
+
+		p, ok := Lookup(source)
+		if !ok {
+			continue
+		}
+		if prefetcher, ok := p.(Prefetcher); ok {
+			byProvider[prefetcher] = append(byProvider[prefetcher], RepoRef{Path: source, Tag: tag})
+		}
+	}
+
+	for prefetcher, lookups := range byProvider {
+		prefetcher.Prefetch(lookups)
+	}
+}
+
+// normalize resolves vanity import paths and strips version suffixes that are not part of the tag
+func normalize(source, version string) (string, string) {
+	return resolve(source), strings.TrimSuffix(version, "+incompatible")
+}
+
 // TODO: check if pkg.go.dev does provide an API to fetch needed information
 // it does a better job regarding license info (see: https://github.com/golang/go/issues/36785)
-func FetchModuleInfo(source, version string) model.RepoInfo {
-	source = resolve(source)
-	tag := strings.TrimSuffix(version, "+incompatible")
+func FetchModuleInfo(source, version string) (model.RepoInfo, error) {
+	source, tag := normalize(source, version)
+
+	p, ok := Lookup(source)
+	if !ok {
+		log.Debug().Msgf("No provider registered for %s", source)
+
+		return model.RepoInfo{}, nil
+	}
 
-	return githubClient.getInfo(source, tag)
+	return p.Info(source, tag)
 }
 
 func FetchLicenseText(source, spdx string) (string, bool) {
@@ -27,17 +84,18 @@ func FetchLicenseText(source, spdx string) (string, bool) {
 
 	source = resolve(source)
 
-	text, err := githubClient.getLicenseFromRepo(source)
+	p, ok := Lookup(source)
+	if !ok {
+		log.Debug().Msgf("No provider registered for %s", source)
+
+		return "", false
+	}
+
+	text, err := p.LicenseText(source, spdx)
 	if err != nil {
 		log.Debug().Err(err).Msgf("Failed to get license text for %s", source)
 
-		// no license file in repo, return general license text
-		text, err = githubClient.getSpdxLicense(spdx)
-		if err != nil {
-			log.Debug().Err(err).Msgf("Failed to get spdx data for %s", spdx)
-
-			return "", false
-		}
+		return "", false
 	}
 
 	return text, true
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"syfttoymlconverter/internal/credentials"
+	"syfttoymlconverter/internal/model"
+)
+
+// Provider fetches repository information from a source code host.
+// Paths are given without scheme, e.g. github.com/integrii/flaggy
+type Provider interface {
+	// Info returns the repository information, the release date is looked up for tag.
+	// The error reports that the repository itself could not be fetched, info may still
+	// be partially filled.
+	Info(path, tag string) (model.RepoInfo, error)
+	// LicenseText returns the license file of the repository or the general text of spdx
+	LicenseText(path, spdx string) (string, error)
+	// ReleaseDate returns the date tag was released or created
+	ReleaseDate(path, tag string) (time.Time, bool)
+}
+
+// Factory creates the provider of a host on first use
+type Factory func(host string) Provider
+
+type registration struct {
+	once     sync.Once
+	factory  Factory
+	provider Provider
+}
+
+var (
+	//nolint:gochecknoglobals // providers by host, filled by Register
+	registry   = map[string]*registration{}
+	registryMu sync.Mutex
+
+	// credentialSource resolves the tokens of the providers
+	credentialSource credentials.Source = credentials.Default("")
+)
+
+func init() {
+	Register("github.com", githubFactory(""))
+	Register("gitlab.com", func(host string) Provider {
+		token, _ := credentialSource.Token(host)
+
+		return newGitlab(host, "", token)
+	})
+}
+
+// SetCredentials replaces the source of the tokens, it has to be called before the first lookup
+func SetCredentials(source credentials.Source) {
+	credentialSource = source
+}
+
+// Register adds or replaces the provider for host. The factory is called on the first lookup.
+func Register(host string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[strings.ToLower(host)] = &registration{factory: factory}
+}
+
+// Lookup returns the provider responsible for the host of path
+func Lookup(path string) (Provider, bool) {
+	host := Host(path)
+
+	registryMu.Lock()
+	r, ok := registry[host]
+	registryMu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	r.once.Do(func() {
+		r.provider = r.factory(host)
+	})
+
+	// the factory returns nil if the provider could not be created
+	return r.provider, r.provider != nil
+}
+
+// Host returns the lower cased host of a repository path or url
+func Host(path string) string {
+	if i := strings.Index(path, "://"); i >= 0 {
+		path = path[i+3:]
+	}
+	if i := strings.Index(path, "/"); i >= 0 {
+		path = path[:i]
+	}
+
+	return strings.ToLower(path)
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"syfttoymlconverter/internal/model"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Repository is a source code repository referenced by a package manifest
+type Repository struct {
+	// Path is the host and path of the repository without scheme and .git suffix,
+	// e.g. github.com/babel/babel
+	Path string
+	// Directory is the directory of the package inside a monorepo
+	Directory string
+}
+
+//nolint:gochecknoglobals // hosts of the npm shorthands like github:user/repo
+var shorthandHosts = map[string]string{
+	"github":    "github.com",
+	"gitlab":    "gitlab.com",
+	"bitbucket": "bitbucket.org",
+}
+
+// scp like ssh urls: git@github.com:babel/babel.git
+var scpRegEx = regexp.MustCompile(`^(?:[^@/]+@)?([^:/]+):([^/].*)$`)
+
+// path segments of browser urls that separate the repository from a ref and directory:
+// github.com/o/r/tree/<ref>/dir, gitlab.com/g/r/-/tree/<ref>/dir, bitbucket.org/o/r/src/<ref>/dir
+//
+//nolint:gochecknoglobals // constant lookup table
+var refMarkers = map[string]bool{"tree": true, "blob": true, "-": true, "src": true}
+
+// NormalizeRepoURL converts a repository url of a package manifest into a Repository.
+// It understands git+https, git+ssh, git:// and scp like urls, the shorthands
+// github:user/repo, gitlab:user/repo, bitbucket:user/repo and user/repo as well
+// as browser urls pointing to a directory of a monorepo.
+func NormalizeRepoURL(raw string) (Repository, bool) {
+	raw = strings.TrimSpace(raw)
+	// the fragment names a commit-ish, not part of the repository
+	if i := strings.Index(raw, "#"); i >= 0 {
+		raw = raw[:i]
+	}
+	if raw == "" {
+		return Repository{}, false
+	}
+
+	var host, path string
+	prefix, rest, hasPrefix := strings.Cut(raw, ":")
+	switch {
+	case strings.Contains(raw, "://"):
+		u, err := url.Parse(strings.TrimPrefix(raw, "git+"))
+		if err != nil || u.Hostname() == "" {
+			return Repository{}, false
+		}
+		host, path = u.Hostname(), u.Path
+	case hasPrefix && shorthandHosts[prefix] != "":
+		host, path = shorthandHosts[prefix], rest
+	case hasPrefix && prefix == "gist":
+		return Repository{}, false
+	case scpRegEx.MatchString(raw):
+		matches := scpRegEx.FindStringSubmatch(raw)
+		host, path = matches[1], matches[2]
+	case !hasPrefix && strings.Count(raw, "/") == 1:
+		// npm shorthand for github
+		host, path = "github.com", raw
+	default:
+		return Repository{}, false
+	}
+
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	var directory string
+	for i, s := range segments {
+		if i < 2 || !refMarkers[s] {
+			continue
+		}
+		rest := segments[i+1:]
+		if s == "-" && len(rest) > 0 {
+			// gitlab: /-/tree/<ref>
+			rest = rest[1:]
+		}
+		if len(rest) > 1 {
+			directory = strings.Join(rest[1:], "/")
+		}
+		segments = segments[:i]
+
+		break
+	}
+	if len(segments) < 2 {
+		return Repository{}, false
+	}
+	segments[len(segments)-1] = strings.TrimSuffix(segments[len(segments)-1], ".git")
+
+	return Repository{
+		Path:      strings.ToLower(host) + "/" + strings.Join(segments, "/"),
+		Directory: directory,
+	}, true
+}
+
+// FetchRepositoryInfo looks up a repository whose release tag is not known exactly,
+// the tags are tried in order until one has a release date.
+func FetchRepositoryInfo(path string, tags []string) (model.RepoInfo, error) {
+	p, ok := Lookup(path)
+	if !ok {
+		log.Debug().Msgf("No provider registered for %s", path)
+
+		return model.RepoInfo{}, nil
+	}
+	if len(tags) == 0 {
+		return p.Info(path, "")
+	}
+
+	info, err := p.Info(path, tags[0])
+	if err != nil {
+		return info, err
+	}
+
+	for _, tag := range tags[1:] {
+		if !info.Release.IsZero() {
+			break
+		}
+		if release, ok := p.ReleaseDate(path, tag); ok {
+			info.Release = release
+		}
+	}
+
+	return info, nil
+}
@@ -0,0 +1,254 @@
+// Package purl parses and formats package urls as specified by
+// https://github.com/package-url/purl-spec, e.g. pkg:npm/%40angular/core@15.0.0
+package purl
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const scheme = "pkg:"
+
+// PackageURL is a parsed package url, all components are decoded
+type PackageURL struct {
+	// Type is the ecosystem like npm, golang or nuget, always lower case
+	Type string
+	// Namespace is the scope, group or module path prefix, e.g. @angular or github.com/pkg
+	Namespace string
+	Name      string
+	Version   string
+	// Qualifiers are extra data like arch or repository_url, keys are lower case
+	Qualifiers map[string]string
+	// Subpath is a path inside the package
+	Subpath string
+}
+
+// Parse parses a package url. Unencoded @ in the namespace, as written by some tools
+// for scoped npm packages, is accepted.
+func Parse(s string) (PackageURL, error) {
+	var p PackageURL
+
+	if len(s) < len(scheme) || !strings.EqualFold(s[:len(scheme)], scheme) {
+		return p, fmt.Errorf("purl %q: missing %s scheme", s, scheme)
+	}
+	rest := strings.TrimLeft(s[len(scheme):], "/")
+
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		subpath, err := decodePath(rest[i+1:], true)
+		if err != nil {
+			return p, fmt.Errorf("purl %q: subpath: %w", s, err)
+		}
+		p.Subpath = subpath
+		rest = rest[:i]
+	}
+
+	if i := strings.LastIndex(rest, "?"); i >= 0 {
+		qualifiers, err := parseQualifiers(rest[i+1:])
+		if err != nil {
+			return p, fmt.Errorf("purl %q: qualifiers: %w", s, err)
+		}
+		if len(qualifiers) > 0 {
+			p.Qualifiers = qualifiers
+		}
+		rest = rest[:i]
+	}
+
+	i := strings.Index(rest, "/")
+	if i <= 0 {
+		return p, fmt.Errorf("purl %q: missing type or name", s)
+	}
+	p.Type = strings.ToLower(rest[:i])
+	if !validType(p.Type) {
+		return p, fmt.Errorf("purl %q: invalid type %q", s, p.Type)
+	}
+	rest = strings.TrimRight(rest[i+1:], "/")
+
+	// the version is only separated in the last segment, so @scope/name is no version
+	if i := strings.LastIndex(rest, "@"); i > strings.LastIndex(rest, "/") {
+		version, err := url.PathUnescape(rest[i+1:])
+		if err != nil {
+			return p, fmt.Errorf("purl %q: version: %w", s, err)
+		}
+		p.Version = version
+		rest = rest[:i]
+	}
+
+	if i := strings.LastIndex(rest, "/"); i >= 0 {
+		namespace, err := decodePath(rest[:i], false)
+		if err != nil {
+			return p, fmt.Errorf("purl %q: namespace: %w", s, err)
+		}
+		p.Namespace = namespace
+		rest = rest[i+1:]
+	}
+
+	name, err := url.PathUnescape(rest)
+	if err != nil {
+		return p, fmt.Errorf("purl %q: name: %w", s, err)
+	}
+	if name == "" {
+		return p, fmt.Errorf("purl %q: missing name", s)
+	}
+	p.Name = name
+
+	p.normalize()
+
+	return p, nil
+}
+
+// Type returns the type of a package url or an empty string if it can not be parsed
+func Type(s string) string {
+	p, err := Parse(s)
+	if err != nil {
+		return ""
+	}
+
+	return p.Type
+}
+
+// FullName returns namespace and name as the ecosystem writes them,
+// e.g. @angular/core or github.com/pkg/errors
+func (p PackageURL) FullName() string {
+	if p.Namespace == "" {
+		return p.Name
+	}
+
+	return p.Namespace + "/" + p.Name
+}
+
+// String returns the canonical form of the package url
+func (p PackageURL) String() string {
+	var b strings.Builder
+
+	b.WriteString(scheme)
+	b.WriteString(strings.ToLower(p.Type))
+	b.WriteString("/")
+	if p.Namespace != "" {
+		b.WriteString(encodePath(p.Namespace))
+		b.WriteString("/")
+	}
+	b.WriteString(escape(p.Name, ""))
+	if p.Version != "" {
+		b.WriteString("@")
+		b.WriteString(escape(p.Version, ":"))
+	}
+
+	if len(p.Qualifiers) > 0 {
+		keys := make([]string, 0, len(p.Qualifiers))
+		for k, v := range p.Qualifiers {
+			if v != "" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for i, k := range keys {
+			if i == 0 {
+				b.WriteString("?")
+			} else {
+				b.WriteString("&")
+			}
+			b.WriteString(strings.ToLower(k))
+			b.WriteString("=")
+			b.WriteString(escape(p.Qualifiers[k], ":/"))
+		}
+	}
+
+	if p.Subpath != "" {
+		b.WriteString("#")
+		b.WriteString(encodePath(p.Subpath))
+	}
+
+	return b.String()
+}
+
+// normalize applies the case rules of the types that define them
+func (p *PackageURL) normalize() {
+	switch p.Type {
+	case "github", "bitbucket":
+		p.Namespace = strings.ToLower(p.Namespace)
+		p.Name = strings.ToLower(p.Name)
+	case "pypi":
+		p.Name = strings.ReplaceAll(strings.ToLower(p.Name), "_", "-")
+	}
+}
+
+func validType(t string) bool {
+	for i, r := range t {
+		switch {
+		case r >= 'a' && r <= 'z', r == '.', r == '+', r == '-':
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	return t != ""
+}
+
+func parseQualifiers(s string) (map[string]string, error) {
+	qualifiers := map[string]string{}
+	for _, pair := range strings.Split(s, "&") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			continue
+		}
+		value, err := url.PathUnescape(value)
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			qualifiers[strings.ToLower(key)] = value
+		}
+	}
+
+	return qualifiers, nil
+}
+
+// decodePath decodes every segment and drops empty ones, subpaths also drop . and ..
+func decodePath(s string, subpath bool) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(s, "/") {
+		segment, err := url.PathUnescape(segment)
+		if err != nil {
+			return "", err
+		}
+		if segment == "" || subpath && (segment == "." || segment == "..") {
+			continue
+		}
+		segments = append(segments, segment)
+	}
+
+	return strings.Join(segments, "/"), nil
+}
+
+func encodePath(s string) string {
+	segments := strings.Split(s, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment, "")
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// escape percent-encodes everything but the unreserved characters and safe
+func escape(s, safe string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', strings.IndexByte(safe, c) >= 0:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+
+	return b.String()
+}
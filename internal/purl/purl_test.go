@@ -0,0 +1,184 @@
+package purl
+
+import (
+	"reflect"
+	"testing"
+)
+
+// the cases follow the test suite of the purl-spec, test-suite-data.json
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		purl      string
+		want      PackageURL
+		canonical string
+		wantErr   bool
+	}{
+		{
+			name:      "scoped npm with encoded @",
+			purl:      "pkg:npm/%40angular/animation@12.3.1",
+			want:      PackageURL{Type: "npm", Namespace: "@angular", Name: "animation", Version: "12.3.1"},
+			canonical: "pkg:npm/%40angular/animation@12.3.1",
+		},
+		{
+			name:      "scoped npm with raw @",
+			purl:      "pkg:npm/@angular/animation@12.3.1",
+			want:      PackageURL{Type: "npm", Namespace: "@angular", Name: "animation", Version: "12.3.1"},
+			canonical: "pkg:npm/%40angular/animation@12.3.1",
+		},
+		{
+			name:      "scoped npm without version",
+			purl:      "pkg:npm/@babel/core",
+			want:      PackageURL{Type: "npm", Namespace: "@babel", Name: "core"},
+			canonical: "pkg:npm/%40babel/core",
+		},
+		{
+			name:      "encoded version",
+			purl:      "pkg:npm/foo@1.0.0%2Bbuild",
+			want:      PackageURL{Type: "npm", Name: "foo", Version: "1.0.0+build"},
+			canonical: "pkg:npm/foo@1.0.0%2Bbuild",
+		},
+		{
+			name: "qualifiers with empty values",
+			purl: "pkg:maven/org.apache.xmlgraphics/batik-anim@1.9.1?repositorY_url=repo.spring.io/release&classifier=sources&empty=&novalue",
+			want: PackageURL{
+				Type:      "maven",
+				Namespace: "org.apache.xmlgraphics",
+				Name:      "batik-anim",
+				Version:   "1.9.1",
+				Qualifiers: map[string]string{
+					"classifier":     "sources",
+					"repository_url": "repo.spring.io/release",
+				},
+			},
+			canonical: "pkg:maven/org.apache.xmlgraphics/batik-anim@1.9.1?classifier=sources&repository_url=repo.spring.io/release",
+		},
+		{
+			name:      "only empty qualifiers",
+			purl:      "pkg:maven/org.apache.commons/io@1.3.4?classifier=",
+			want:      PackageURL{Type: "maven", Namespace: "org.apache.commons", Name: "io", Version: "1.3.4"},
+			canonical: "pkg:maven/org.apache.commons/io@1.3.4",
+		},
+		{
+			name: "qualifiers and version with colon",
+			purl: "pkg:docker/customer/dockerimage@sha256:244fd47e07d10?repository_url=gcr.io",
+			want: PackageURL{
+				Type:       "docker",
+				Namespace:  "customer",
+				Name:       "dockerimage",
+				Version:    "sha256:244fd47e07d10",
+				Qualifiers: map[string]string{"repository_url": "gcr.io"},
+			},
+			canonical: "pkg:docker/customer/dockerimage@sha256:244fd47e07d10?repository_url=gcr.io",
+		},
+		{
+			name:      "subpath with . and ..",
+			purl:      "pkg:golang/google.golang.org/genproto#/googleapis/../api/./annotations/",
+			want:      PackageURL{Type: "golang", Namespace: "google.golang.org", Name: "genproto", Subpath: "googleapis/api/annotations"},
+			canonical: "pkg:golang/google.golang.org/genproto#googleapis/api/annotations",
+		},
+		{
+			name:      "golang keeps the case",
+			purl:      "pkg:golang/github.com/Azure/azure-sdk-for-go@v68.0.0+incompatible",
+			want:      PackageURL{Type: "golang", Namespace: "github.com/Azure", Name: "azure-sdk-for-go", Version: "v68.0.0+incompatible"},
+			canonical: "pkg:golang/github.com/Azure/azure-sdk-for-go@v68.0.0%2Bincompatible",
+		},
+		{
+			name:      "nuget keeps the case",
+			purl:      "pkg:nuget/EnterpriseLibrary.Common@6.0.1304",
+			want:      PackageURL{Type: "nuget", Name: "EnterpriseLibrary.Common", Version: "6.0.1304"},
+			canonical: "pkg:nuget/EnterpriseLibrary.Common@6.0.1304",
+		},
+		{
+			name:      "pypi normalization",
+			purl:      "pkg:PYPI/Django_package@1.11.1.dev1",
+			want:      PackageURL{Type: "pypi", Name: "django-package", Version: "1.11.1.dev1"},
+			canonical: "pkg:pypi/django-package@1.11.1.dev1",
+		},
+		{
+			name:      "github normalization",
+			purl:      "pkg:GitHub/Package-URL/Purl-Spec@244fd47e07d1004f0aed9c",
+			want:      PackageURL{Type: "github", Namespace: "package-url", Name: "purl-spec", Version: "244fd47e07d1004f0aed9c"},
+			canonical: "pkg:github/package-url/purl-spec@244fd47e07d1004f0aed9c",
+		},
+		{
+			name:      "slashes after the scheme",
+			purl:      "pkg://npm/foo@1.0.0",
+			want:      PackageURL{Type: "npm", Name: "foo", Version: "1.0.0"},
+			canonical: "pkg:npm/foo@1.0.0",
+		},
+		{
+			name:    "type starting with a digit",
+			purl:    "pkg:3npm/foo@1.0.0",
+			wantErr: true,
+		},
+		{
+			name:    "type with invalid characters",
+			purl:    "pkg:n&m/foo@1.0.0",
+			wantErr: true,
+		},
+		{
+			name:    "missing scheme",
+			purl:    "npm/foo@1.0.0",
+			wantErr: true,
+		},
+		{
+			name:    "missing name",
+			purl:    "pkg:maven/@1.3.4",
+			wantErr: true,
+		},
+		{
+			name:    "missing type",
+			purl:    "pkg:foo",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.purl)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Parse(%q) = %+v, want an error", tt.purl, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.purl, got, tt.want)
+			}
+			if s := got.String(); s != tt.canonical {
+				t.Errorf("String() = %q, want %q", s, tt.canonical)
+			}
+
+			again, err := Parse(tt.canonical)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(again, tt.want) || again.String() != tt.canonical {
+				t.Errorf("%q does not round-trip: %+v", tt.canonical, again)
+			}
+		})
+	}
+}
+
+func TestFullName(t *testing.T) {
+	tests := map[string]string{
+		"pkg:npm/%40angular/core@15.0.0":              "@angular/core",
+		"pkg:npm/tslib@2.4.0":                         "tslib",
+		"pkg:golang/github.com/pkg/errors@v0.9.1":     "github.com/pkg/errors",
+		"pkg:nuget/Newtonsoft.Json@13.0.1":            "Newtonsoft.Json",
+		"pkg:conan/openssl@3.0.8?channel=stable#conf": "openssl",
+	}
+	for s, want := range tests {
+		p, err := Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := p.FullName(); got != want {
+			t.Errorf("FullName of %s = %q, want %q", s, got, want)
+		}
+	}
+}
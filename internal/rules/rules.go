@@ -0,0 +1,257 @@
+// Package rules decides which modules become libraries of the document
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"syfttoymlconverter/internal/model"
+	"syfttoymlconverter/internal/purl"
+
+	"github.com/goccy/go-yaml"
+)
+
+// actions of a rule
+const (
+	Include = "include"
+	Exclude = "exclude"
+)
+
+// Rule matches modules by all of its conditions, empty conditions match everything.
+// Globs use path.Match, so * does not match a slash.
+type Rule struct {
+	Action string `yaml:"action"`
+	// Reason is written to the report for every module the rule excludes
+	Reason string `yaml:"reason,omitempty"`
+
+	// Name are globs for the package name like @types/* or github.com/pkg/*
+	Name []string `yaml:"name,omitempty"`
+	// Ecosystem are the ecosystems npm, go, dotnet or conan
+	Ecosystem []string `yaml:"ecosystem,omitempty"`
+	// License are globs for the SPDX license, an empty glob matches a missing license
+	License []string `yaml:"license,omitempty"`
+	// Scope are the dependency scopes like dev, test or excluded, as far as the SBOM records them
+	Scope []string `yaml:"scope,omitempty"`
+	// Direct restricts the rule to direct (true) or transitive (false) dependencies
+	Direct *bool `yaml:"direct,omitempty"`
+	// MinDepth and MaxDepth restrict the distance to the project, 0 is no restriction
+	MinDepth int `yaml:"minDepth,omitempty"`
+	MaxDepth int `yaml:"maxDepth,omitempty"`
+}
+
+// Config is the rules file. The first matching rule decides, modules no rule
+// matches get the default action which is include.
+//
+//	default: include
+//	rules:
+//	  - action: exclude
+//	    reason: build tooling is not shipped
+//	    scope: [dev, test]
+//	  - action: exclude
+//	    reason: only the first two levels are documented
+//	    minDepth: 3
+type Config struct {
+	Default string `yaml:"default,omitempty"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+// Exclusion records why a module was left out
+type Exclusion struct {
+	Module string `yaml:"module"`
+	Rule   int    `yaml:"rule"`
+	Reason string `yaml:"reason"`
+}
+
+// Report lists the excluded modules of a run
+type Report struct {
+	Included   int         `yaml:"included"`
+	Exclusions []Exclusion `yaml:"exclusions"`
+}
+
+// Load reads a rules file
+func Load(file string) (Config, error) {
+	var config Config
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return config, err
+	}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("parsing %s: %w", file, err)
+	}
+
+	return config, config.Validate()
+}
+
+// Validate checks the actions and globs of all rules
+func (c Config) Validate() error {
+	if c.Default != "" && c.Default != Include && c.Default != Exclude {
+		return fmt.Errorf("default: unknown action %q", c.Default)
+	}
+
+	for i, r := range c.Rules {
+		if r.Action != Include && r.Action != Exclude {
+			return fmt.Errorf("rule %d: unknown action %q, expected %s or %s", i+1, r.Action, Include, Exclude)
+		}
+		for _, glob := range append(append([]string{}, r.Name...), r.License...) {
+			if _, err := path.Match(glob, ""); err != nil {
+				return fmt.Errorf("rule %d: invalid glob %q: %w", i+1, glob, err)
+			}
+		}
+		if r.MaxDepth != 0 && r.MinDepth > r.MaxDepth {
+			return fmt.Errorf("rule %d: minDepth %d is greater than maxDepth %d", i+1, r.MinDepth, r.MaxDepth)
+		}
+	}
+
+	return nil
+}
+
+// UsesScope reports whether a rule matches by the dependency scope
+func (c Config) UsesScope() bool {
+	for _, r := range c.Rules {
+		if len(r.Scope) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Apply returns the modules to include and the report of the excluded ones
+func (c Config) Apply(modules []model.Module) ([]model.Module, Report) {
+	var report Report
+	var included []model.Module
+
+	for _, m := range modules {
+		action, rule, reason := c.decide(m)
+		if action == Exclude {
+			report.Exclusions = append(report.Exclusions, Exclusion{
+				Module: m.String(),
+				Rule:   rule,
+				Reason: reason,
+			})
+			continue
+		}
+		included = append(included, m)
+	}
+	report.Included = len(included)
+
+	return included, report
+}
+
+// decide returns the action for m, the number of the matching rule (0 for the
+// default) and the reason
+func (c Config) decide(m model.Module) (string, int, string) {
+	for i, r := range c.Rules {
+		if !r.Matches(m) {
+			continue
+		}
+
+		reason := r.Reason
+		if reason == "" {
+			reason = r.String()
+		}
+
+		return r.Action, i + 1, reason
+	}
+
+	if c.Default == Exclude {
+		return Exclude, 0, "no rule includes it"
+	}
+
+	return Include, 0, ""
+}
+
+// Matches reports whether all conditions of the rule hold for m
+func (r Rule) Matches(m model.Module) bool {
+	if len(r.Name) > 0 && !matchAny(r.Name, Name(m)) {
+		return false
+	}
+	if len(r.Ecosystem) > 0 && !containsFold(r.Ecosystem, m.Ecosystem) {
+		return false
+	}
+	if len(r.License) > 0 && !matchAny(r.License, m.Info.SPDX) {
+		return false
+	}
+	if len(r.Scope) > 0 && !containsFold(r.Scope, m.Scope) {
+		return false
+	}
+	if r.Direct != nil && *r.Direct != m.Direct {
+		return false
+	}
+	if r.MinDepth > 0 && m.Depth < r.MinDepth {
+		return false
+	}
+	if r.MaxDepth > 0 && m.Depth > r.MaxDepth {
+		return false
+	}
+
+	return true
+}
+
+// String describes the conditions of the rule, it is the reason of rules without one
+func (r Rule) String() string {
+	var conditions []string
+	if len(r.Name) > 0 {
+		conditions = append(conditions, "name "+strings.Join(r.Name, " or "))
+	}
+	if len(r.Ecosystem) > 0 {
+		conditions = append(conditions, "ecosystem "+strings.Join(r.Ecosystem, " or "))
+	}
+	if len(r.License) > 0 {
+		conditions = append(conditions, "license "+strings.Join(r.License, " or "))
+	}
+	if len(r.Scope) > 0 {
+		conditions = append(conditions, "scope "+strings.Join(r.Scope, " or "))
+	}
+	if r.Direct != nil && *r.Direct {
+		conditions = append(conditions, "direct dependency")
+	}
+	if r.Direct != nil && !*r.Direct {
+		conditions = append(conditions, "transitive dependency")
+	}
+	if r.MinDepth > 0 {
+		conditions = append(conditions, fmt.Sprintf("depth >= %d", r.MinDepth))
+	}
+	if r.MaxDepth > 0 {
+		conditions = append(conditions, fmt.Sprintf("depth <= %d", r.MaxDepth))
+	}
+	if len(conditions) == 0 {
+		return r.Action + " all"
+	}
+
+	return r.Action + " " + strings.Join(conditions, ", ")
+}
+
+// Name returns the package name of m as its ecosystem writes it
+func Name(m model.Module) string {
+	if p, err := purl.Parse(m.Purl); err == nil {
+		return p.FullName()
+	}
+	if m.Name != "" {
+		return m.Name
+	}
+
+	return m.Path
+}
+
+func matchAny(globs []string, s string) bool {
+	for _, glob := range globs {
+		if ok, _ := path.Match(glob, s); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Format is an SBOM format the converter reads
+type Format string
+
+const (
+	FormatSyft          Format = "syft-json"
+	FormatCycloneDXJSON Format = "cyclonedx-json"
+	FormatCycloneDXXML  Format = "cyclonedx-xml"
+	FormatSPDXJSON      Format = "spdx-json"
+	FormatSPDXTagValue  Format = "spdx-tag-value"
+)
+
+// ReadSBOMFile reads the SBOM at path, see ReadSBOM
+func ReadSBOMFile(path string) (*Syft, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ReadSBOM(file)
+}
+
+// ReadSBOM detects the format of the SBOM from its content and converts it
+func ReadSBOM(r io.Reader) (*Syft, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	format, err := DetectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatCycloneDXJSON:
+		return ReadCycloneDXJSON(data)
+	case FormatCycloneDXXML:
+		return ReadCycloneDXXML(data)
+	case FormatSPDXJSON:
+		return ReadSPDXJSON(data)
+	case FormatSPDXTagValue:
+		return ReadSPDXTagValue(data)
+	}
+
+	syft := &Syft{}
+	return syft.Read(bytes.NewReader(data))
+}
+
+// DetectFormat returns the format of an SBOM document
+func DetectFormat(data []byte) (Format, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	trimmed := bytes.TrimLeft(data, " \t\r\n")
+	if len(trimmed) == 0 {
+		return "", errors.New("empty SBOM")
+	}
+
+	switch trimmed[0] {
+	case '{':
+		return detectJSON(trimmed)
+	case '<':
+		return detectXML(trimmed)
+	}
+	if isSPDXTagValue(trimmed) {
+		return FormatSPDXTagValue, nil
+	}
+
+	return "", errors.New("unknown SBOM format, expected syft json, CycloneDX json or xml or SPDX json or tag-value")
+}
+
+func detectJSON(data []byte) (Format, error) {
+	var probe struct {
+		BomFormat   string          `json:"bomFormat"`
+		SpdxVersion string          `json:"spdxVersion"`
+		Artifacts   json.RawMessage `json:"artifacts"`
+		Schema      struct {
+			URL string `json:"url"`
+		} `json:"schema"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return "", fmt.Errorf("parsing SBOM: %w", err)
+	}
+
+	switch {
+	case strings.EqualFold(probe.BomFormat, "CycloneDX"):
+		return FormatCycloneDXJSON, nil
+	case probe.SpdxVersion != "":
+		return FormatSPDXJSON, nil
+	case probe.Artifacts != nil, strings.Contains(probe.Schema.URL, "syft"):
+		return FormatSyft, nil
+	}
+
+	return "", errors.New("unknown json SBOM format, expected syft, CycloneDX or SPDX")
+}
+
+func detectXML(data []byte) (Format, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("parsing SBOM: %w", err)
+		}
+
+		if start, ok := token.(xml.StartElement); ok {
+			if start.Name.Local == "bom" && strings.Contains(start.Name.Space, "cyclonedx.org") {
+				return FormatCycloneDXXML, nil
+			}
+
+			return "", fmt.Errorf("unknown xml SBOM format with root element %s", start.Name.Local)
+		}
+	}
+}
+
+// isSPDXTagValue reports whether the first tag of a text document is SPDXVersion
+func isSPDXTagValue(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		return strings.HasPrefix(line, "SPDXVersion:")
+	}
+
+	return false
+}
@@ -0,0 +1,295 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"syfttoymlconverter/internal/purl"
+)
+
+// spdxDocument is the subset of an SPDX 2.x document the converter needs.
+// The tag-value reader fills the same structure.
+type spdxDocument struct {
+	SpdxVersion       string             `json:"spdxVersion"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentDescribes []string           `json:"documentDescribes"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxPackage struct {
+	SPDXID           string `json:"SPDXID"`
+	Name             string `json:"name"`
+	VersionInfo      string `json:"versionInfo"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+	ExternalRefs     []struct {
+		ReferenceCategory string `json:"referenceCategory"`
+		ReferenceType     string `json:"referenceType"`
+		ReferenceLocator  string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+	Checksums []struct {
+		Algorithm     string `json:"algorithm"`
+		ChecksumValue string `json:"checksumValue"`
+	} `json:"checksums"`
+}
+
+type spdxRelationship struct {
+	SpdxElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSpdxElement string `json:"relatedSpdxElement"`
+}
+
+// scopes of the SPDX dependency relationships, plain dependencies have none
+//
+//nolint:gochecknoglobals // constant lookup table
+var spdxDependencyScopes = map[string]string{
+	"DEPENDENCY_OF":          "",
+	"RUNTIME_DEPENDENCY_OF":  "",
+	"DEV_DEPENDENCY_OF":      "dev",
+	"DEV_TOOL_OF":            "dev",
+	"TEST_DEPENDENCY_OF":     "test",
+	"BUILD_DEPENDENCY_OF":    "build",
+	"BUILD_TOOL_OF":          "build",
+	"OPTIONAL_DEPENDENCY_OF": "optional",
+	"PROVIDED_DEPENDENCY_OF": "provided",
+}
+
+// ReadSPDXJSON converts an SPDX json document
+func ReadSPDXJSON(data []byte) (*Syft, error) {
+	var doc spdxDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	return doc.toSyft(), nil
+}
+
+// ReadSPDXTagValue converts an SPDX tag-value document
+func ReadSPDXTagValue(data []byte) (*Syft, error) {
+	var doc spdxDocument
+	var pkg *spdxPackage
+	inPackage := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		tag, value, ok := strings.Cut(text, ":")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected tag: value", line)
+		}
+		value = strings.TrimSpace(value)
+
+		// multi-line values are enclosed in <text>, none of them is needed
+		if strings.HasPrefix(value, "<text>") {
+			for !strings.Contains(value, "</text>") && scanner.Scan() {
+				line++
+				value = scanner.Text()
+			}
+			continue
+		}
+
+		switch tag {
+		case "SPDXVersion":
+			doc.SpdxVersion = value
+		case "DocumentName":
+			doc.Name = value
+		case "PackageName":
+			doc.Packages = append(doc.Packages, spdxPackage{Name: value})
+			pkg = &doc.Packages[len(doc.Packages)-1]
+			inPackage = true
+		case "FileName", "SnippetSPDXID", "LicenseID":
+			// files, snippets and extracted licenses end the package section
+			inPackage = false
+		case "SPDXID":
+			if inPackage {
+				pkg.SPDXID = value
+			} else if doc.SPDXID == "" {
+				doc.SPDXID = value
+			}
+		case "PackageVersion":
+			if inPackage {
+				pkg.VersionInfo = value
+			}
+		case "PackageLicenseConcluded":
+			if inPackage {
+				pkg.LicenseConcluded = value
+			}
+		case "PackageLicenseDeclared":
+			if inPackage {
+				pkg.LicenseDeclared = value
+			}
+		case "PackageChecksum":
+			if inPackage {
+				algorithm, checksum, _ := strings.Cut(value, ":")
+				pkg.Checksums = append(pkg.Checksums, struct {
+					Algorithm     string `json:"algorithm"`
+					ChecksumValue string `json:"checksumValue"`
+				}{strings.TrimSpace(algorithm), strings.TrimSpace(checksum)})
+			}
+		case "ExternalRef":
+			fields := strings.Fields(value)
+			if inPackage && len(fields) >= 3 {
+				pkg.ExternalRefs = append(pkg.ExternalRefs, struct {
+					ReferenceCategory string `json:"referenceCategory"`
+					ReferenceType     string `json:"referenceType"`
+					ReferenceLocator  string `json:"referenceLocator"`
+				}{fields[0], fields[1], fields[2]})
+			}
+		case "Relationship":
+			fields := strings.Fields(value)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("line %d: invalid relationship %q", line, value)
+			}
+			doc.Relationships = append(doc.Relationships, spdxRelationship{
+				SpdxElementID:      fields[0],
+				RelationshipType:   fields[1],
+				RelatedSpdxElement: fields[2],
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return doc.toSyft(), nil
+}
+
+// toSyft maps the packages to artifacts and the relationships to syft relationships.
+// The packages the document describes are the scanned project, they become the source.
+func (doc spdxDocument) toSyft() *Syft {
+	syft := &Syft{
+		Schema: Schema{Version: strings.TrimPrefix(doc.SpdxVersion, "SPDX-"), URL: "https://spdx.org/rdf/terms"},
+		Source: Source{ID: doc.SPDXID, Type: "spdx", Target: doc.Name},
+	}
+
+	described := map[string]bool{}
+	for _, id := range doc.DocumentDescribes {
+		described[id] = true
+	}
+	for _, r := range doc.Relationships {
+		switch {
+		case r.RelationshipType == "DESCRIBES" && r.SpdxElementID == doc.SPDXID:
+			described[r.RelatedSpdxElement] = true
+		case r.RelationshipType == "DESCRIBED_BY" && r.RelatedSpdxElement == doc.SPDXID:
+			described[r.SpdxElementID] = true
+		}
+	}
+
+	// dependencies with a scope only get it if they are not also a plain dependency
+	scopes := map[string]string{}
+	runtime := map[string]bool{}
+
+	for _, r := range doc.Relationships {
+		from, to := r.SpdxElementID, r.RelatedSpdxElement
+		if described[from] {
+			from = syft.Source.ID
+		}
+		if described[to] {
+			to = syft.Source.ID
+		}
+
+		relationshipType := r.RelationshipType
+		if relationshipType == "DEPENDS_ON" {
+			// A DEPENDS_ON B is B DEPENDENCY_OF A
+			relationshipType, from, to = "DEPENDENCY_OF", to, from
+		}
+
+		if scope, ok := spdxDependencyScopes[relationshipType]; ok {
+			syft.ArtifactRelationships = append(syft.ArtifactRelationships, Relationship{
+				Parent: from,
+				Child:  to,
+				Type:   relationshipDependencyOf,
+			})
+			if scope == "" {
+				runtime[from] = true
+			} else if scopes[from] == "" {
+				scopes[from] = scope
+			}
+			continue
+		}
+
+		switch relationshipType {
+		case "CONTAINS":
+			syft.ArtifactRelationships = append(syft.ArtifactRelationships, Relationship{Parent: from, Child: to, Type: relationshipContains})
+		case "CONTAINED_BY":
+			syft.ArtifactRelationships = append(syft.ArtifactRelationships, Relationship{Parent: to, Child: from, Type: relationshipContains})
+		}
+	}
+
+	for _, p := range doc.Packages {
+		if described[p.SPDXID] {
+			if syft.Source.Target == "" {
+				syft.Source.Target = p.Name
+			}
+			continue
+		}
+
+		a := p.toArtifact()
+		if !runtime[a.ID] {
+			a.Scope = scopes[a.ID]
+		}
+		syft.Artifacts = append(syft.Artifacts, a)
+		syft.ArtifactRelationships = append(syft.ArtifactRelationships, Relationship{
+			Parent: syft.Source.ID,
+			Child:  a.ID,
+			Type:   relationshipContains,
+		})
+	}
+
+	return syft
+}
+
+func (p spdxPackage) toArtifact() Artifact {
+	a := Artifact{
+		ID:      p.SPDXID,
+		Name:    p.Name,
+		Version: p.VersionInfo,
+	}
+
+	for _, ref := range p.ExternalRefs {
+		category := strings.ReplaceAll(ref.ReferenceCategory, "_", "-")
+		switch {
+		case category == "PACKAGE-MANAGER" && ref.ReferenceType == "purl" && a.Purl == "":
+			a.Purl = ref.ReferenceLocator
+		case category == "SECURITY" && strings.HasPrefix(ref.ReferenceType, "cpe"):
+			a.Cpes = append(a.Cpes, ref.ReferenceLocator)
+		}
+	}
+	if parsed, err := purl.Parse(a.Purl); err == nil {
+		a.Name = parsed.FullName()
+		a.Type = parsed.Type
+	}
+
+	if license := spdxLicense(p.LicenseConcluded); license != "" {
+		a.Licenses = []string{license}
+	} else if license := spdxLicense(p.LicenseDeclared); license != "" {
+		a.Licenses = []string{license}
+	}
+
+	for _, c := range p.Checksums {
+		a.Digests = append(a.Digests, Digest{Algorithm: c.Algorithm, Value: c.ChecksumValue})
+	}
+
+	return a
+}
+
+// spdxLicense returns the license expression, empty for NOASSERTION and NONE
+func spdxLicense(expression string) string {
+	switch expression {
+	case "NOASSERTION", "NONE":
+		return ""
+	}
+
+	return expression
+}
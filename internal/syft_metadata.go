@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// NpmMetadata is the metadata of a package-lock.json entry
+type NpmMetadata struct {
+	// Resolved is the url of the tarball
+	Resolved string `json:"resolved"`
+	// Integrity is the subresource integrity of the tarball, e.g. sha512-<base64>
+	Integrity string `json:"integrity"`
+}
+
+// DotnetMetadata is the metadata of a deps.json entry
+type DotnetMetadata struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Path is the package directory, e.g. newtonsoft.json/13.0.1
+	Path string `json:"path"`
+	// Sha512 is the hash of the nupkg, e.g. sha512-<base64>
+	Sha512 string `json:"sha512"`
+	// HashPath is the file holding the hash, e.g. newtonsoft.json.13.0.1.nupkg.sha512
+	HashPath string `json:"hashPath"`
+}
+
+// ConanMetadata is the metadata of a conanfile or conan.lock entry
+type ConanMetadata struct {
+	// Ref is the reference of the recipe, e.g. zlib/1.2.13@user/channel#revision
+	Ref string `json:"ref"`
+}
+
+// GoMetadata is the metadata of a go module from a binary or a go.mod
+type GoMetadata struct {
+	GoCompiledVersion string `json:"goCompiledVersion"`
+	Architecture      string `json:"architecture"`
+	MainModule        string `json:"mainModule"`
+}
+
+// metadataTypes maps the metadataType of old and current syft schemas to the metadata struct.
+// Metadata of other types is kept as map.
+//
+//nolint:gochecknoglobals // constant lookup table
+var metadataTypes = map[string]func(data []byte) (interface{}, error){
+	"NpmPackageLockJsonMetadata":        decodeMetadata[NpmMetadata],
+	"javascript-npm-package-lock-entry": decodeMetadata[NpmMetadata],
+	"DotnetDepsMetadata":                decodeMetadata[DotnetMetadata],
+	"dotnet-deps-entry":                 decodeMetadata[DotnetMetadata],
+	"ConanMetadataType":                 decodeMetadata[ConanMetadata],
+	"ConanLockMetadataType":             decodeMetadata[ConanMetadata],
+	"c-conan-file-entry":                decodeMetadata[ConanMetadata],
+	"c-conan-lock-entry":                decodeMetadata[ConanMetadata],
+	"c-conan-lock-v2-entry":             decodeMetadata[ConanMetadata],
+	"c-conan-info-entry":                decodeMetadata[ConanMetadata],
+	"GolangBinMetadata":                 decodeMetadata[GoMetadata],
+	"GolangModMetadata":                 decodeMetadata[GoMetadata],
+	"go-module-buildinfo-entry":         decodeMetadata[GoMetadata],
+	"go-module-entry":                   decodeMetadata[GoMetadata],
+}
+
+// decodeMetadata decodes data into a T, handlers switch on the type of the metadata
+func decodeMetadata[T any](data []byte) (interface{}, error) {
+	var metadata T
+	err := json.Unmarshal(data, &metadata)
+	return metadata, err
+}
+
+// Licenses are the licenses of an artifact. Syft writes plain strings up to
+// schema 7 and objects with value and spdxExpression since.
+type Licenses []string
+
+func (l *Licenses) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*l = nil
+	for _, item := range raw {
+		var value string
+		if err := json.Unmarshal(item, &value); err == nil {
+			*l = append(*l, value)
+			continue
+		}
+
+		var license struct {
+			Value          string `json:"value"`
+			SPDXExpression string `json:"spdxExpression"`
+		}
+		if err := json.Unmarshal(item, &license); err != nil {
+			return fmt.Errorf("license %s: %w", item, err)
+		}
+		if license.SPDXExpression != "" {
+			*l = append(*l, license.SPDXExpression)
+		} else if license.Value != "" {
+			*l = append(*l, license.Value)
+		}
+	}
+
+	return nil
+}
+
+// Cpes are the cpes of an artifact. Syft writes plain strings up to schema 15
+// and objects with cpe and source since.
+type Cpes []string
+
+func (c *Cpes) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	*c = nil
+	for _, item := range raw {
+		var value string
+		if err := json.Unmarshal(item, &value); err == nil {
+			*c = append(*c, value)
+			continue
+		}
+
+		var cpe struct {
+			Cpe string `json:"cpe"`
+		}
+		if err := json.Unmarshal(item, &cpe); err != nil {
+			return fmt.Errorf("cpe %s: %w", item, err)
+		}
+		*c = append(*c, cpe.Cpe)
+	}
+
+	return nil
+}
+
+// UnmarshalJSON decodes the metadata into the struct of its metadataType
+func (a *Artifact) UnmarshalJSON(data []byte) error {
+	type plain Artifact
+	var raw struct {
+		plain
+		Metadata json.RawMessage `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*a = Artifact(raw.plain)
+
+	metadata := bytes.TrimSpace(raw.Metadata)
+	if len(metadata) == 0 || bytes.Equal(metadata, []byte("null")) {
+		return nil
+	}
+
+	if decode, ok := metadataTypes[a.MetadataType]; ok {
+		typed, err := decode(metadata)
+		if err != nil {
+			return fmt.Errorf("%s metadata of %s: %w", a.MetadataType, a.Name, err)
+		}
+		a.Metadata = typed
+		return nil
+	}
+
+	var untyped map[string]interface{}
+	if err := json.Unmarshal(metadata, &untyped); err == nil {
+		a.Metadata = untyped
+	}
+
+	return nil
+}
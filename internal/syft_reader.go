@@ -2,50 +2,121 @@ package internal
 
 import (
 	"encoding/json"
-	"log"
+	"io"
 	"os"
+	"syfttoymlconverter/internal/purl"
 )
 
+// Syft is the internal representation of an SBOM, the syft json schema.
+// Other formats are converted into it by their readers.
 type Syft struct {
-	Artifacts []struct {
-		ID        string `json:"id"`
-		Name      string `json:"name"`
-		Version   string `json:"version"`
-		Type      string `json:"type"`
-		FoundBy   string `json:"foundBy"`
-		Locations []struct {
-			Path string `json:"path"`
-		} `json:"locations"`
-		//Licenses     []interface{} `json:"licenses"`
-		Licenses     []string `json:"licenses"`
-		Language     string   `json:"language"`
-		Cpes         []string `json:"cpes"`
-		Purl         string   `json:"purl"`
-		MetadataType string   `json:"metadataType"`
-		Metadata     struct {
-		} `json:"metadata"`
-	} `json:"artifacts"`
-	ArtifactRelationships []struct {
-		Parent string `json:"parent"`
-		Child  string `json:"child"`
-		Type   string `json:"type"`
-	} `json:"artifactRelationships"`
-	Source struct {
-		ID     string `json:"id"`
-		Type   string `json:"type"`
-		Target string `json:"target"`
-	} `json:"source"`
-	Schema struct {
-		Version string `json:"version"`
-		URL     string `json:"url"`
-	} `json:"schema"`
+	Artifacts             []Artifact     `json:"artifacts"`
+	ArtifactRelationships []Relationship `json:"artifactRelationships"`
+	Source                Source         `json:"source"`
+	Schema                Schema         `json:"schema"`
+}
+
+// Artifact is a package found by syft
+type Artifact struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Version      string     `json:"version"`
+	Type         string     `json:"type"`
+	FoundBy      string     `json:"foundBy"`
+	Locations    []Location `json:"locations"`
+	Licenses     Licenses   `json:"licenses"`
+	Language     string     `json:"language"`
+	Cpes         Cpes       `json:"cpes"`
+	Purl         string     `json:"purl"`
+	MetadataType string     `json:"metadataType"`
+	// Metadata is NpmMetadata, DotnetMetadata, ConanMetadata or GoMetadata depending
+	// on the MetadataType, a map for other types and nil if there is none
+	Metadata interface{} `json:"metadata"`
+	// Digests are the hashes of the package given by other SBOM formats
+	Digests []Digest `json:"digests,omitempty"`
+	// Scope is the dependency scope like dev or test given by other SBOM formats
+	Scope string `json:"scope,omitempty"`
+}
+
+// Location is a file an artifact was found in
+type Location struct {
+	Path string `json:"path"`
+}
+
+// Digest is a hash of an artifact
+type Digest struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"value"`
+}
+
+// Relationship links two artifacts or the source and an artifact.
+// For dependency-of the parent is a dependency of the child.
+type Relationship struct {
+	Parent string `json:"parent"`
+	Child  string `json:"child"`
+	Type   string `json:"type"`
+}
+
+// Source is the scanned project
+type Source struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Target string `json:"target"`
+}
+
+// Schema is the format of the SBOM
+type Schema struct {
+	Version string `json:"version"`
+	URL     string `json:"url"`
+}
+
+// Path returns the location of the first artifact, empty if it has none
+func (syft *Syft) Path() string {
+	if len(syft.Artifacts) == 0 || len(syft.Artifacts[0].Locations) == 0 {
+		return ""
+	}
+
+	return syft.Artifacts[0].Locations[0].Path
 }
 
 func (syft *Syft) ReadJson(path string) (*Syft, error) {
-	file, err := os.ReadFile(path)
+	file, err := os.Open(path)
 	if err != nil {
-		log.Print(err)
+		return syft, err
 	}
-	err = json.Unmarshal(file, syft)
+	defer file.Close()
+
+	return syft.Read(file)
+}
+
+// Read decodes a syft json document from r, e.g. os.Stdin
+func (syft *Syft) Read(r io.Reader) (*Syft, error) {
+	err := json.NewDecoder(r).Decode(syft)
 	return syft, err
 }
+
+// Partition splits the artifacts by their purl type. Every part keeps the source
+// and schema of the original SBOM and the relationships of its own artifacts.
+func (syft *Syft) Partition() map[string]*Syft {
+	parts := map[string]*Syft{}
+	owner := map[string]string{}
+
+	for _, a := range syft.Artifacts {
+		purlType := purl.Type(a.Purl)
+		part, ok := parts[purlType]
+		if !ok {
+			part = &Syft{Source: syft.Source, Schema: syft.Schema}
+			parts[purlType] = part
+		}
+		part.Artifacts = append(part.Artifacts, a)
+		owner[a.ID] = purlType
+	}
+
+	for _, r := range syft.ArtifactRelationships {
+		if purlType, ok := owner[r.Child]; ok {
+			parts[purlType].ArtifactRelationships = append(parts[purlType].ArtifactRelationships, r)
+		}
+	}
+
+	return parts
+}
@@ -2,16 +2,15 @@ package internal
 
 import (
 	"sort"
-	"strings"
 	"syfttoymlconverter/internal/model"
+	"syfttoymlconverter/internal/purl"
 )
 
 func ToolToDependencies(syft *Syft) *model.SBOM {
 	result := &model.SBOM{}
 
 	for _, d := range syft.Artifacts {
-		//Generating Language out of the start of the purl
-		language := strings.Split(d.Purl, "/")[0][4:]
+		language := purl.Type(d.Purl)
 
 		if !contains(result.Languages, language) {
 			result.Languages = append(result.Languages, language)
@@ -32,6 +31,7 @@ func ToolToDependencies(syft *Syft) *model.SBOM {
 			Licenses:   d.Licenses,
 			//Not sure if we should keep purl for tool independency
 			Purl:     d.Purl,
+			Scope:    d.Scope,
 			ID:       d.ID,
 			TopLevel: toplevel,
 		})
@@ -0,0 +1,136 @@
+// Package validate checks the validate struct tags of the document model.
+// It understands the rules required, omitempty and dive of go-playground/validator:
+// structs are always checked, slice and map elements only with dive.
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Named is implemented by list elements that should be named in violations, like libraries
+type Named interface {
+	Name() string
+}
+
+// Violation is a field that breaks a rule of its tag
+type Violation struct {
+	// Path is the yaml path of the field, e.g. libraries[3].libraryTable.manufacturer
+	Path string
+	Rule string
+	// Element is the name of the nearest Named element containing the field
+	Element string
+}
+
+func (v Violation) String() string {
+	if v.Element != "" {
+		return fmt.Sprintf("%s: %s (%s)", v.Path, v.Rule, v.Element)
+	}
+
+	return fmt.Sprintf("%s: %s", v.Path, v.Rule)
+}
+
+// Struct checks the tags of v, a struct or a pointer to one
+func Struct(v interface{}) []Violation {
+	var violations []Violation
+	walk(reflect.ValueOf(v), "", "", &violations)
+
+	return violations
+}
+
+func walk(v reflect.Value, path, element string, violations *[]Violation) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	if named, ok := v.Interface().(Named); ok {
+		element = named.Name()
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		checkField(v.Field(i), join(path, fieldName(f)), element, f.Tag.Get("validate"), violations)
+	}
+}
+
+func checkField(v reflect.Value, path, element, tag string, violations *[]Violation) {
+	dive := false
+	for _, rule := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(rule) {
+		case "omitempty":
+			if v.IsZero() {
+				return
+			}
+		case "required":
+			if empty(v) {
+				*violations = append(*violations, Violation{Path: path, Rule: "required", Element: element})
+				return
+			}
+		case "dive":
+			dive = true
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		if !dive {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), fmt.Sprintf("%s[%d]", path, i), element, violations)
+		}
+	case reflect.Map:
+		if !dive {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			walk(iter.Value(), fmt.Sprintf("%s.%v", path, iter.Key()), element, violations)
+		}
+	default:
+		walk(v, path, element, violations)
+	}
+}
+
+// empty is the required check: zero values and empty lists fail
+func empty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	}
+
+	return v.IsZero()
+}
+
+// fieldName returns the yaml key of a field, the json key or the field name
+func fieldName(f reflect.StructField) string {
+	for _, key := range []string{"yaml", "json"} {
+		name := strings.Split(f.Tag.Get(key), ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return f.Name
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}